@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"devshort-backend/internal/config"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
@@ -12,16 +16,33 @@ func main() {
 	validate := config.NewValidator(viperConfig)
 	app := config.NewFiber(viperConfig)
 	producer := config.NewKafkaProducer(viperConfig, log)
+	asyncProducer := config.NewKafkaAsyncProducer(viperConfig, log)
+
+	// cancelled once the server has stopped, ends the background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	config.Bootstrap(&config.BootstrapConfig{
-		DB:       db,
-		App:      app,
-		Log:      log,
-		Validate: validate,
-		Config:   viperConfig,
-		Producer: producer,
+		DB:            db,
+		App:           app,
+		Log:           log,
+		Validate:      validate,
+		Config:        viperConfig,
+		Producer:      producer,
+		AsyncProducer: asyncProducer,
+		Context:       ctx,
 	})
 
+	go func() {
+		terminateSignals := make(chan os.Signal, 1)
+		signal.Notify(terminateSignals, syscall.SIGINT, syscall.SIGTERM)
+		s := <-terminateSignals
+		log.Info("Got one of stop signals, shutting down server gracefully, SIGNAL NAME :", s)
+		if err := app.Shutdown(); err != nil {
+			log.WithError(err).Error("Failed to shut down server")
+		}
+	}()
+
 	webPort := viperConfig.GetInt("web.port")
 	err := app.Listen(fmt.Sprintf(":%d", webPort))
 	if err != nil {
@@ -4,6 +4,9 @@ import (
 	"context"
 	"devshort-backend/internal/config"
 	"devshort-backend/internal/delivery/messaging"
+	gatewayMessaging "devshort-backend/internal/gateway/messaging"
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,16 +14,20 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"gorm.io/gorm"
 )
 
 func main() {
 	viperConfig := config.NewViper()
 	logger := config.NewLogger(viperConfig)
 	logger.Info("Starting worker service")
+	db := config.NewDatabase(viperConfig, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go RunUserConsumer(logger, viperConfig, ctx)
+	go RunClickConsumer(logger, viperConfig, db, ctx)
+	go RunLinkExpiryJob(logger, viperConfig, db, ctx)
 
 	terminateSignals := make(chan os.Signal, 1)
 	signal.Notify(terminateSignals, syscall.SIGINT, syscall.SIGKILL, syscall.SIGTERM)
@@ -44,3 +51,46 @@ func RunUserConsumer(logger *logrus.Logger, viperConfig *viper.Viper, ctx contex
 	userHandler := messaging.NewUserConsumer(logger)
 	messaging.ConsumeTopic(ctx, userConsumerGroup, "users", logger, userHandler.Consume)
 }
+
+func RunClickConsumer(logger *logrus.Logger, viperConfig *viper.Viper, db *gorm.DB, ctx context.Context) {
+	logger.Info("setup click consumer")
+	clickConsumerGroup := config.NewKafkaConsumerGroup(viperConfig, logger)
+	validate := config.NewValidator(viperConfig)
+	linkStatsUseCase := usecase.NewLinkStatsUseCase(db, logger, validate,
+		repository.NewLinkRepository(logger), repository.NewLinkStatsRepository(logger), repository.NewLinkClickRepository(logger))
+	clickHandler := messaging.NewClickConsumer(logger, linkStatsUseCase)
+	messaging.ConsumeTopic(ctx, clickConsumerGroup, "clicks", logger, clickHandler.Consume)
+}
+
+func RunLinkExpiryJob(logger *logrus.Logger, viperConfig *viper.Viper, db *gorm.DB, ctx context.Context) {
+	logger.Info("setup link expiry job")
+	var linkProducer *gatewayMessaging.LinkProducer
+	if producer := config.NewKafkaProducer(viperConfig, logger); producer != nil {
+		linkProducer = gatewayMessaging.NewLinkProducer(producer, logger)
+	}
+	linkExpiryUseCase := usecase.NewLinkExpiryUseCase(db, logger, repository.NewLinkRepository(logger), linkProducer)
+
+	interval := time.Duration(viperConfig.GetInt("link.expiry.interval_seconds")) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			total, err := linkExpiryUseCase.DeactivateExpired(ctx)
+			if err != nil {
+				logger.WithError(err).Error("Failed to deactivate expired links")
+			}
+			if total > 0 {
+				logger.Infof("Deactivated %d expired links", total)
+			}
+		case <-ctx.Done():
+			logger.Info("Context cancelled, stopping link expiry job")
+			return
+		}
+	}
+}
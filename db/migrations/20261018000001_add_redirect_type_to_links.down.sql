@@ -0,0 +1,2 @@
+alter table links
+    drop column redirect_type;
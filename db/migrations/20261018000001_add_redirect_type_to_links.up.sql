@@ -0,0 +1,2 @@
+alter table links
+    add column redirect_type int not null default 302 after long_url;
@@ -0,0 +1 @@
+drop table link_sequences;
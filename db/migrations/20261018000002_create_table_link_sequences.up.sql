@@ -0,0 +1,6 @@
+create table link_sequences
+(
+    id         bigint not null auto_increment,
+    created_at bigint not null,
+    primary key (id)
+) engine = InnoDB;
@@ -0,0 +1,2 @@
+alter table links
+    modify column short_url varchar(50) null;
@@ -0,0 +1,2 @@
+alter table links
+    modify column short_url varchar(50) character set utf8mb4 collate utf8mb4_bin null;
@@ -0,0 +1,6 @@
+drop table link_country_stats;
+drop table link_browser_stats;
+drop table link_referrer_stats;
+drop table link_visitors;
+drop table link_daily_stats;
+drop table click_offsets;
@@ -0,0 +1,55 @@
+create table click_offsets
+(
+    topic          varchar(100) not null,
+    partition_id   int          not null,
+    offset_id      bigint       not null,
+    processed_at   bigint       not null,
+    primary key (topic, partition_id, offset_id)
+) engine = InnoDB;
+
+create table link_daily_stats
+(
+    link_id         varchar(100) not null,
+    day             varchar(10)  not null,
+    clicks          bigint       not null default 0,
+    unique_visitors bigint       not null default 0,
+    primary key (link_id, day),
+    foreign key fk_link_daily_stats_link_id (link_id) references links (id) on delete cascade
+) engine = InnoDB;
+
+create table link_visitors
+(
+    link_id    varchar(100) not null,
+    day        varchar(10)  not null,
+    ip_hash    varchar(64)  not null,
+    primary key (link_id, day, ip_hash),
+    key idx_link_visitors_ip_hash (link_id, ip_hash),
+    foreign key fk_link_visitors_link_id (link_id) references links (id) on delete cascade
+) engine = InnoDB;
+
+create table link_referrer_stats
+(
+    link_id  varchar(100) not null,
+    referrer varchar(255) not null,
+    clicks   bigint       not null default 0,
+    primary key (link_id, referrer),
+    foreign key fk_link_referrer_stats_link_id (link_id) references links (id) on delete cascade
+) engine = InnoDB;
+
+create table link_browser_stats
+(
+    link_id varchar(100) not null,
+    browser varchar(50)  not null,
+    clicks  bigint       not null default 0,
+    primary key (link_id, browser),
+    foreign key fk_link_browser_stats_link_id (link_id) references links (id) on delete cascade
+) engine = InnoDB;
+
+create table link_country_stats
+(
+    link_id varchar(100) not null,
+    country varchar(10)  not null,
+    clicks  bigint       not null default 0,
+    primary key (link_id, country),
+    foreign key fk_link_country_stats_link_id (link_id) references links (id) on delete cascade
+) engine = InnoDB;
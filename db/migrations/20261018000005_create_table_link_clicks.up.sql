@@ -0,0 +1,15 @@
+create table link_clicks
+(
+    id         varchar(100) not null,
+    link_id    varchar(100) not null,
+    clicked_at bigint       not null,
+    referrer   varchar(255) not null,
+    device     varchar(20)  not null,
+    os         varchar(50)  not null,
+    browser    varchar(50)  not null,
+    country    varchar(10)  not null,
+    ip_hash    varchar(64)  null,
+    primary key (id),
+    key idx_link_clicks_link_id_clicked_at (link_id, clicked_at),
+    foreign key fk_link_clicks_link_id (link_id) references links (id) on delete cascade
+) engine = InnoDB;
@@ -0,0 +1 @@
+drop index idx_links_user_id_created_at_id on links;
@@ -0,0 +1 @@
+create index idx_links_user_id_created_at_id on links (user_id, created_at, id);
@@ -0,0 +1,5 @@
+alter table links
+    drop index idx_links_is_active_expires_at,
+    drop column click_count,
+    drop column max_clicks,
+    drop column expires_at;
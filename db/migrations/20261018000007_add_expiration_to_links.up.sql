@@ -0,0 +1,5 @@
+alter table links
+    add column expires_at  bigint null after is_active,
+    add column max_clicks  bigint null after expires_at,
+    add column click_count bigint not null default 0 after max_clicks,
+    add index idx_links_is_active_expires_at (is_active, expires_at);
@@ -0,0 +1,2 @@
+alter table links
+    add column password varchar(100) null after redirect_type;
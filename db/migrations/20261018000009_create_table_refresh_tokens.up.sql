@@ -0,0 +1,15 @@
+create table refresh_tokens
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    family_id  varchar(100) not null,
+    token_hash varchar(64)  not null,
+    expires_at bigint       not null,
+    used_at    bigint       null,
+    revoked_at bigint       null,
+    created_at bigint       not null,
+    primary key (id),
+    unique key uk_refresh_tokens_token_hash (token_hash),
+    key idx_refresh_tokens_family_id (family_id),
+    foreign key fk_refresh_tokens_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
@@ -0,0 +1,13 @@
+create table token_revocations
+(
+    id            varchar(100) not null,
+    jti           varchar(100) null,
+    user_id       varchar(100) not null,
+    issued_before bigint       null,
+    expires_at    bigint       not null,
+    created_at    bigint       not null,
+    primary key (id),
+    unique key uk_token_revocations_jti (jti),
+    key idx_token_revocations_created_at (created_at),
+    key idx_token_revocations_expires_at (expires_at)
+) engine = InnoDB;
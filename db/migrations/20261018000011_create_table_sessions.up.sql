@@ -0,0 +1,12 @@
+create table sessions
+(
+    id           varchar(100) not null,
+    user_id      varchar(100) not null,
+    user_agent   varchar(255) not null,
+    ip_hash      varchar(64)  null,
+    created_at   bigint       not null,
+    last_seen_at bigint       not null,
+    primary key (id),
+    key idx_sessions_user_id_last_seen_at (user_id, last_seen_at),
+    foreign key fk_sessions_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
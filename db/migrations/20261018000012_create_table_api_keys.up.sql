@@ -0,0 +1,16 @@
+create table api_keys
+(
+    id           varchar(100) not null,
+    user_id      varchar(100) not null,
+    name         varchar(100) not null,
+    prefix       varchar(20)  not null,
+    key_hash     varchar(64)  not null,
+    scopes       varchar(255) not null,
+    expires_at   bigint       null,
+    last_used_at bigint       null,
+    created_at   bigint       not null,
+    primary key (id),
+    unique key uk_api_keys_key_hash (key_hash),
+    key idx_api_keys_user_id (user_id),
+    foreign key fk_api_keys_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
@@ -0,0 +1,5 @@
+drop table user_tokens;
+
+alter table users
+    drop index uk_users_email,
+    drop column email;
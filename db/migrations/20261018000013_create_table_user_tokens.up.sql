@@ -0,0 +1,18 @@
+alter table users
+    add column email varchar(255) null after name,
+    add unique key uk_users_email (email);
+
+create table user_tokens
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    purpose    varchar(20)  not null,
+    token_hash varchar(64)  not null,
+    expires_at bigint       not null,
+    used_at    bigint       null,
+    created_at bigint       not null,
+    primary key (id),
+    unique key uk_user_tokens_token_hash (token_hash),
+    key idx_user_tokens_user_id_purpose (user_id, purpose),
+    foreign key fk_user_tokens_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
@@ -0,0 +1,2 @@
+alter table users
+    add column verified_at bigint null after email;
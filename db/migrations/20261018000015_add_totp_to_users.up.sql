@@ -0,0 +1,16 @@
+alter table users
+    add column totp_secret     varchar(64) null after verified_at,
+    add column totp_enabled_at bigint      null after totp_secret,
+    add column totp_last_step  bigint      not null default 0 after totp_enabled_at;
+
+create table recovery_codes
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    code_hash  varchar(64)  not null,
+    used_at    bigint       null,
+    created_at bigint       not null,
+    primary key (id),
+    unique key uk_recovery_codes_user_id_code_hash (user_id, code_hash),
+    foreign key fk_recovery_codes_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
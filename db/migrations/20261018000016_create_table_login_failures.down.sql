@@ -0,0 +1,4 @@
+drop table login_failures;
+
+alter table users
+    drop column locked_until;
@@ -0,0 +1,10 @@
+alter table users
+    add column locked_until bigint null after totp_last_step;
+
+create table login_failures
+(
+    subject        varchar(150) not null,
+    failures       int          not null,
+    last_failed_at bigint       not null,
+    primary key (subject)
+) engine = InnoDB;
@@ -0,0 +1,5 @@
+alter table users
+    drop foreign key fk_users_plan_id,
+    drop column plan_id;
+
+drop table plans;
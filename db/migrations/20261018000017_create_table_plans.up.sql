@@ -0,0 +1,16 @@
+create table plans
+(
+    id                varchar(50)  not null,
+    name              varchar(100) not null,
+    max_links         int          not null default 0,
+    max_links_per_day int          not null default 0,
+    primary key (id)
+) engine = InnoDB;
+
+insert into plans (id, name, max_links, max_links_per_day)
+values ('free', 'Free', 100, 20),
+       ('pro', 'Pro', 10000, 1000);
+
+alter table users
+    add column plan_id varchar(50) not null default 'free' after locked_until,
+    add foreign key fk_users_plan_id (plan_id) references plans (id);
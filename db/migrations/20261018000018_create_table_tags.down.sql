@@ -0,0 +1,3 @@
+drop table link_tags;
+
+drop table tags;
@@ -0,0 +1,21 @@
+create table tags
+(
+    id         varchar(100) not null,
+    user_id    varchar(100) not null,
+    name       varchar(50)  not null,
+    created_at bigint       not null,
+    updated_at bigint       not null,
+    primary key (id),
+    unique key uk_tags_user_id_name (user_id, name),
+    foreign key fk_tags_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
+
+create table link_tags
+(
+    link_id varchar(100) not null,
+    tag_id  varchar(100) not null,
+    primary key (link_id, tag_id),
+    key idx_link_tags_tag_id (tag_id),
+    foreign key fk_link_tags_link_id (link_id) references links (id) on delete cascade,
+    foreign key fk_link_tags_tag_id (tag_id) references tags (id) on delete cascade
+) engine = InnoDB;
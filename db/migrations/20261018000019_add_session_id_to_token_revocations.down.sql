@@ -0,0 +1,2 @@
+alter table token_revocations
+    drop column session_id;
@@ -0,0 +1,2 @@
+alter table token_revocations
+    add column session_id varchar(100) null after user_id;
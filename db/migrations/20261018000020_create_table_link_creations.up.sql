@@ -0,0 +1,13 @@
+create table link_creations
+(
+    user_id varchar(100) not null,
+    day     varchar(10)  not null,
+    links   int          not null default 0,
+    primary key (user_id, day),
+    foreign key fk_link_creations_user_id (user_id) references users (id) on delete cascade
+) engine = InnoDB;
+
+insert into link_creations (user_id, day, links)
+select user_id, date_add('1970-01-01', interval created_at div 86400000 day) as created_day, count(*)
+from links
+group by user_id, created_day;
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"context"
 	"devshort-backend/internal/delivery/http"
 	"devshort-backend/internal/delivery/http/middleware"
 	"devshort-backend/internal/delivery/http/route"
 	"devshort-backend/internal/gateway/messaging"
 	"devshort-backend/internal/repository"
 	"devshort-backend/internal/usecase"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/go-playground/validator/v10"
@@ -17,18 +19,44 @@ import (
 )
 
 type BootstrapConfig struct {
-	DB       *gorm.DB
-	App      *fiber.App
-	Log      *logrus.Logger
-	Validate *validator.Validate
-	Config   *viper.Viper
-	Producer sarama.SyncProducer
+	DB            *gorm.DB
+	App           *fiber.App
+	Log           *logrus.Logger
+	Validate      *validator.Validate
+	Config        *viper.Viper
+	Producer      sarama.SyncProducer
+	AsyncProducer sarama.AsyncProducer
+	// Clock defaults to the system clock, tests replace it to move time
+	Clock usecase.Clock
+	// Context stops the background work started here once it is done,
+	// defaults to a context that never ends
+	Context context.Context
 }
 
 func Bootstrap(config *BootstrapConfig) {
+	if config.Clock == nil {
+		config.Clock = usecase.SystemClock{}
+	}
+	if config.Context == nil {
+		config.Context = context.Background()
+	}
+
 	// setup repositories
 	userRepository := repository.NewUserRepository(config.Log)
 	linkRepository := repository.NewLinkRepository(config.Log)
+	linkSequenceRepository := repository.NewLinkSequenceRepository(config.Log)
+	linkStatsRepository := repository.NewLinkStatsRepository(config.Log)
+	linkClickRepository := repository.NewLinkClickRepository(config.Log)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(config.Log)
+	tokenRevocationRepository := repository.NewTokenRevocationRepository(config.Log)
+	sessionRepository := repository.NewSessionRepository(config.Log)
+	apiKeyRepository := repository.NewApiKeyRepository(config.Log)
+	userTokenRepository := repository.NewUserTokenRepository(config.Log)
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(config.Log)
+	loginFailureRepository := repository.NewLoginFailureRepository(config.Log)
+	planRepository := repository.NewPlanRepository(config.Log)
+	linkCreationRepository := repository.NewLinkCreationRepository(config.Log)
+	tagRepository := repository.NewTagRepository(config.Log)
 
 	// setup producer
 	var userProducer *messaging.UserProducer
@@ -39,22 +67,98 @@ func Bootstrap(config *BootstrapConfig) {
 		linkProducer = messaging.NewLinkProducer(config.Producer, config.Log)
 	}
 
+	var clickProducer *messaging.ClickProducer
+
+	if config.AsyncProducer != nil {
+		clickProducer = messaging.NewClickProducer(config.AsyncProducer, config.Log, config.Config.GetInt("kafka.producer.buffer_size"))
+	}
+
+	// setup link policies
+	shortCodeGenerator := NewShortCodeGenerator(config.Config, config.Log, linkSequenceRepository)
+	aliasPolicy := NewAliasPolicy(config.Config)
+	cursorCodec := NewCursorCodec(config.Config, config.Log)
+	linkUnlocker := NewLinkUnlocker(config.Config, config.Log)
+	linkQuota := usecase.NewLinkQuota(config.Log, linkRepository, linkCreationRepository, planRepository, config.Clock)
+	tokenService := NewTokenService(config.Config, config.Log)
+	tokenRevoker := NewTokenRevoker(config.Context, config.Config, config.DB, config.Log, tokenRevocationRepository)
+	mailSender := NewMailSender(config.Config, config.Log)
+	passwordPolicy := NewPasswordPolicy(config.Config, config.Log)
+	loginThrottle := NewLoginThrottle(config.Config, config.Log, config.Clock, loginFailureRepository)
+
 	// setup use cases
-	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository, userProducer)
-	linkUseCase := usecase.NewLinkUseCase(config.DB, config.Log, config.Validate, linkRepository, userRepository,linkProducer)
+	sessionUseCase := usecase.NewSessionUseCase(config.DB, config.Log, config.Validate, sessionRepository, refreshTokenRepository,
+		config.Config.GetString("session.ip_salt"), time.Duration(config.Config.GetInt("session.touch_interval_seconds"))*time.Second)
+	tagUseCase := usecase.NewTagUseCase(config.DB, config.Log, config.Validate, tagRepository)
+	apiKeyUseCase := usecase.NewApiKeyUseCase(config.DB, config.Log, config.Validate, apiKeyRepository, userRepository)
+	emailVerificationUseCase := usecase.NewEmailVerificationUseCase(config.DB, config.Log, config.Validate, userRepository, userTokenRepository,
+		mailSender, config.Config.GetString("email_verification.url"),
+		time.Duration(config.Config.GetInt("email_verification.token_ttl_seconds"))*time.Second)
+	mfaUseCase := usecase.NewMfaUseCase(config.DB, config.Log, config.Validate, userRepository, userTokenRepository, recoveryCodeRepository,
+		config.Config.GetString("mfa.issuer"), time.Duration(config.Config.GetInt("mfa.challenge_ttl_seconds"))*time.Second,
+		config.Config.GetInt("mfa.recovery_codes"))
+	accountLockUseCase := usecase.NewAccountLockUseCase(config.DB, config.Log, config.Validate, userRepository, userTokenRepository,
+		loginThrottle, mailSender, userProducer, config.Clock,
+		time.Duration(config.Config.GetInt("login_protection.lockout_seconds"))*time.Second,
+		config.Config.GetString("login_protection.unlock_url"),
+		time.Duration(config.Config.GetInt("login_protection.unlock_token_ttl_seconds"))*time.Second)
+	userUseCase := usecase.NewUserUseCase(config.DB, config.Log, config.Validate, userRepository, refreshTokenRepository, tokenService, tokenRevoker, sessionUseCase,
+		emailVerificationUseCase, mfaUseCase, passwordPolicy, accountLockUseCase, linkQuota, userProducer,
+		time.Duration(config.Config.GetInt("auth.access_token_ttl_seconds"))*time.Second,
+		time.Duration(config.Config.GetInt("auth.refresh_token_ttl_seconds"))*time.Second)
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(config.DB, config.Log, config.Validate, userRepository, userTokenRepository,
+		apiKeyRepository, sessionUseCase, tokenRevoker, mailSender, passwordPolicy, config.Config.GetString("password_reset.url"),
+		time.Duration(config.Config.GetInt("password_reset.token_ttl_seconds"))*time.Second,
+		time.Duration(config.Config.GetInt("auth.access_token_ttl_seconds"))*time.Second)
+	linkUseCase := usecase.NewLinkUseCase(config.DB, config.Log, config.Validate, linkRepository, userRepository, linkProducer,
+		shortCodeGenerator, config.Config.GetInt("shortcode.attempts"), aliasPolicy, cursorCodec,
+		config.Config.GetBool("email_verification.required_for_links"), linkQuota, tagUseCase)
+	redirectUseCase := usecase.NewRedirectUseCase(config.DB, config.Log, config.Validate, linkRepository, aliasPolicy,
+		clickProducer, config.Config.GetString("click.ip_salt"), linkUnlocker)
+	linkStatsUseCase := usecase.NewLinkStatsUseCase(config.DB, config.Log, config.Validate, linkRepository, linkStatsRepository, linkClickRepository)
+	linkAnalyticsUseCase := usecase.NewLinkAnalyticsUseCase(config.DB, config.Log, config.Validate, linkRepository, linkClickRepository)
 
 	// setup controller
 	userController := http.NewUserController(userUseCase, config.Log)
 	linkController := http.NewLinkController(linkUseCase, config.Log)
+	redirectController := http.NewRedirectController(redirectUseCase, config.Log, config.Config.GetString("click.country_header"),
+		config.Config.GetString("link.expired.fallback_url"))
+	linkStatsController := http.NewLinkStatsController(linkStatsUseCase, config.Log)
+	linkAnalyticsController := http.NewLinkAnalyticsController(linkAnalyticsUseCase, config.Log)
+	sessionController := http.NewSessionController(sessionUseCase, config.Log)
+	apiKeyController := http.NewApiKeyController(apiKeyUseCase, config.Log)
+	jwksController := http.NewJwksController(tokenService, config.Log)
+	passwordResetController := http.NewPasswordResetController(passwordResetUseCase, config.Log)
+	emailVerificationController := http.NewEmailVerificationController(emailVerificationUseCase, config.Log)
+	mfaController := http.NewMfaController(mfaUseCase, config.Log)
+	accountLockController := http.NewAccountLockController(accountLockUseCase, config.Log)
+	tagController := http.NewTagController(tagUseCase, config.Log)
 
 	// setup middleware
-	authMiddleware := middleware.NewAuth()
+	authMiddleware := middleware.NewAuth(userUseCase, sessionUseCase)
+	apiKeyMiddleware := middleware.NewApiKeyAuth(apiKeyUseCase)
+	rateLimiter := NewRateLimiter(config.Config, config.Log, config.Clock)
 
 	routeConfig := route.RouteConfig{
-		App:               config.App,
-		UserController:    userController,
-		LinkController:    linkController,
-		AuthMiddleware:    authMiddleware,
+		App:                         config.App,
+		UserController:              userController,
+		LinkController:              linkController,
+		RedirectController:          redirectController,
+		LinkStatsController:         linkStatsController,
+		LinkAnalyticsController:     linkAnalyticsController,
+		SessionController:           sessionController,
+		ApiKeyController:            apiKeyController,
+		JwksController:              jwksController,
+		PasswordResetController:     passwordResetController,
+		EmailVerificationController: emailVerificationController,
+		MfaController:               mfaController,
+		AccountLockController:       accountLockController,
+		TagController:               tagController,
+		ApiKeyMiddleware:            apiKeyMiddleware,
+		AuthMiddleware:              authMiddleware,
+		GuestRateLimit:              NewRateLimit(config.Config, rateLimiter, "guest"),
+		AuthRateLimit:               NewRateLimit(config.Config, rateLimiter, "auth"),
+		AuthIpRateLimit:             NewRateLimit(config.Config, rateLimiter, "auth_ip"),
+		LinkCreateRateLimit:         NewRateLimit(config.Config, rateLimiter, "link_create"),
 	}
 	routeConfig.Setup()
 }
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// NewTokenRevoker loads the revocations that are still in effect and keeps
+// them in sync with other instances in the background until ctx is done
+func NewTokenRevoker(ctx context.Context, config *viper.Viper, db *gorm.DB, log *logrus.Logger,
+	tokenRevocationRepository *repository.TokenRevocationRepository) *usecase.TokenRevoker {
+	config.SetDefault("auth.revocation_sync_seconds", 10)
+
+	revoker := usecase.NewTokenRevoker(db, log, tokenRevocationRepository)
+	if err := revoker.Sync(ctx); err != nil {
+		log.WithError(err).Warn("Failed to load token revocations")
+	}
+
+	interval := time.Duration(config.GetInt("auth.revocation_sync_seconds")) * time.Second
+	go revoker.Run(ctx, interval)
+
+	return revoker
+}
+
+func NewLoginThrottle(config *viper.Viper, log *logrus.Logger, clock usecase.Clock,
+	loginFailureRepository *repository.LoginFailureRepository) *usecase.LoginThrottle {
+	config.SetDefault("login_protection.free_attempts", 3)
+	config.SetDefault("login_protection.ip_free_attempts", 20)
+	config.SetDefault("login_protection.base_delay_seconds", 1)
+	config.SetDefault("login_protection.max_delay_seconds", 300)
+	config.SetDefault("login_protection.lockout_threshold", 10)
+	config.SetDefault("login_protection.reset_after_seconds", 86400)
+
+	return usecase.NewLoginThrottle(log, loginFailureRepository, clock,
+		config.GetString("session.ip_salt"),
+		config.GetInt("login_protection.free_attempts"),
+		config.GetInt("login_protection.ip_free_attempts"),
+		time.Duration(config.GetInt("login_protection.base_delay_seconds"))*time.Second,
+		time.Duration(config.GetInt("login_protection.max_delay_seconds"))*time.Second,
+		config.GetInt("login_protection.lockout_threshold"),
+		time.Duration(config.GetInt("login_protection.reset_after_seconds"))*time.Second,
+	)
+}
@@ -1,29 +1,47 @@
 package config
 
 import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
 )
 
+// NewFiber creates the app. The client IP, which keys rate limits and login
+// throttling, is read from web.proxy_header only when the request comes from
+// one of web.trusted_proxies; anyone else could send the header themselves.
 func NewFiber(config *viper.Viper) *fiber.App {
 	var app = fiber.New(fiber.Config{
-		AppName:      config.GetString("app.name"),
-		ErrorHandler: NewErrorHandler(),
-		Prefork:      config.GetBool("web.prefork"),
+		AppName:                 config.GetString("app.name"),
+		ErrorHandler:            NewErrorHandler(),
+		Prefork:                 config.GetBool("web.prefork"),
+		ProxyHeader:             config.GetString("web.proxy_header"),
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          config.GetStringSlice("web.trusted_proxies"),
+		EnableIPValidation:      true,
 	})
 
 	return app
 }
 
+// NewErrorHandler renders every error as a model.WebResponse carrying the
+// code and details of its *usecase.AppError
 func NewErrorHandler() fiber.ErrorHandler {
 	return func(ctx *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
-		if e, ok := err.(*fiber.Error); ok {
-			code = e.Code
+		appErr := usecase.ToAppError(err)
+		if appErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
 		}
 
-		return ctx.Status(code).JSON(fiber.Map{
-			"errors": err.Error(),
+		return ctx.Status(appErr.Status).JSON(model.WebResponse[any]{
+			Data:    appErr.Data,
+			Errors:  appErr.Message,
+			Code:    appErr.Code,
+			Details: appErr.Details,
 		})
 	}
 }
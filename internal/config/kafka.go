@@ -2,6 +2,7 @@ package config
 
 import (
 	"strings"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/sirupsen/logrus"
@@ -48,3 +49,32 @@ func NewKafkaProducer(config *viper.Viper, log *logrus.Logger) sarama.SyncProduc
 	}
 	return producer
 }
+
+func NewKafkaAsyncProducer(config *viper.Viper, log *logrus.Logger) sarama.AsyncProducer {
+	if !config.GetBool("kafka.producer.enabled") {
+		log.Info("Kafka async producer is disabled")
+		return nil
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Producer.Return.Successes = false
+	saramaConfig.Producer.Return.Errors = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForLocal
+	saramaConfig.Producer.Retry.Max = 3
+	saramaConfig.Producer.Flush.Frequency = 500 * time.Millisecond
+
+	brokers := strings.Split(config.GetString("kafka.bootstrap.servers"), ",")
+
+	producer, err := sarama.NewAsyncProducer(brokers, saramaConfig)
+	if err != nil {
+		log.Fatalf("Failed to create async producer: %v", err)
+	}
+
+	go func() {
+		for err := range producer.Errors() {
+			log.WithError(err.Err).Errorf("Failed to produce message to topic %s", err.Msg.Topic)
+		}
+	}()
+
+	return producer
+}
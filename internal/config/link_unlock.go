@@ -0,0 +1,27 @@
+package config
+
+import (
+	"devshort-backend/internal/usecase"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewLinkUnlocker(config *viper.Viper, log *logrus.Logger) *usecase.LinkUnlocker {
+	config.SetDefault("link.unlock.token_ttl_seconds", 3600)
+	config.SetDefault("link.unlock.max_attempts", 5)
+	config.SetDefault("link.unlock.window_seconds", 300)
+
+	secret := config.GetString("link.unlock.secret")
+	if secret == "" {
+		log.Fatal("link.unlock.secret must be configured to sign unlock cookies")
+	}
+
+	return usecase.NewLinkUnlocker(
+		[]byte(secret),
+		time.Duration(config.GetInt("link.unlock.token_ttl_seconds"))*time.Second,
+		config.GetInt("link.unlock.max_attempts"),
+		time.Duration(config.GetInt("link.unlock.window_seconds"))*time.Second,
+	)
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"devshort-backend/internal/gateway/mail"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewMailSender picks the mail backend from mail.driver: smtp, file or log
+func NewMailSender(config *viper.Viper, log *logrus.Logger) mail.Sender {
+	from := config.GetString("mail.from")
+
+	switch driver := config.GetString("mail.driver"); driver {
+	case "smtp":
+		return mail.NewSmtpSender(
+			config.GetString("mail.smtp.host"),
+			config.GetInt("mail.smtp.port"),
+			config.GetString("mail.smtp.username"),
+			config.GetString("mail.smtp.password"),
+			from,
+			log,
+		)
+	case "file":
+		return mail.NewFileSender(config.GetString("mail.file.dir"), from, log)
+	case "log", "":
+		return mail.NewLogSender(log)
+	default:
+		log.Fatalf("Unknown mail driver %q", driver)
+		return nil
+	}
+}
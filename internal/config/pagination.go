@@ -0,0 +1,16 @@
+package config
+
+import (
+	"devshort-backend/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewCursorCodec(config *viper.Viper, log *logrus.Logger) *usecase.CursorCodec {
+	secret := config.GetString("pagination.cursor_secret")
+	if secret == "" {
+		log.Fatal("pagination.cursor_secret must be configured to sign cursors")
+	}
+	return usecase.NewCursorCodec([]byte(secret))
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"devshort-backend/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewPasswordPolicy(config *viper.Viper, log *logrus.Logger) *usecase.PasswordPolicy {
+	config.SetDefault("password.min_length", 8)
+	config.SetDefault("password.max_length", 72)
+
+	policy := &usecase.PasswordPolicy{
+		MinLength:        config.GetInt("password.min_length"),
+		MaxLength:        config.GetInt("password.max_length"),
+		RequireUpper:     config.GetBool("password.require_upper"),
+		RequireLower:     config.GetBool("password.require_lower"),
+		RequireDigit:     config.GetBool("password.require_digit"),
+		RequireSymbol:    config.GetBool("password.require_symbol"),
+		DisallowUserInfo: config.GetBool("password.disallow_user_info"),
+	}
+
+	if path := config.GetString("password.breached_file"); path != "" {
+		breached, err := usecase.NewBreachedPasswords(configPath(config, path))
+		if err != nil {
+			log.Fatalf("Failed to open breached password list: %v", err)
+		}
+		policy.Breached = breached
+	}
+
+	return policy
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewRateLimiter picks the bucket store from rate_limit.store, only memory
+// ships with the app
+func NewRateLimiter(config *viper.Viper, log *logrus.Logger, clock usecase.Clock) *usecase.RateLimiter {
+	var store usecase.RateLimitStore
+	switch driver := config.GetString("rate_limit.store"); driver {
+	case "memory", "":
+		store = usecase.NewMemoryRateLimitStore()
+	default:
+		log.Fatalf("Unknown rate limit store %q", driver)
+	}
+
+	return usecase.NewRateLimiter(log, store, clock)
+}
+
+// NewRateLimit builds the middleware for the policy rate_limit.<name>. A
+// limit of zero turns the policy off.
+func NewRateLimit(config *viper.Viper, rateLimiter *usecase.RateLimiter, name string) fiber.Handler {
+	config.SetDefault("rate_limit."+name+".window_seconds", 60)
+
+	limit := &usecase.RateLimit{
+		Name:   name,
+		Limit:  config.GetInt("rate_limit." + name + ".limit"),
+		Window: time.Duration(config.GetInt("rate_limit."+name+".window_seconds")) * time.Second,
+	}
+	if limit.Limit <= 0 || limit.Window <= 0 {
+		return func(ctx *fiber.Ctx) error {
+			return ctx.Next()
+		}
+	}
+
+	return middleware.NewRateLimit(rateLimiter, limit)
+}
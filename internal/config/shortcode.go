@@ -0,0 +1,36 @@
+package config
+
+import (
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewShortCodeGenerator(config *viper.Viper, log *logrus.Logger, sequenceRepository *repository.LinkSequenceRepository) usecase.ShortCodeGenerator {
+	length := config.GetInt("shortcode.length")
+	if length <= 0 {
+		length = 7
+	}
+
+	strategy := config.GetString("shortcode.strategy")
+	switch strategy {
+	case "", "random":
+		return usecase.NewRandomShortCodeGenerator(length)
+	case "counter":
+		if length > usecase.MaxCounterShortCodeLength {
+			log.Fatalf("Short code length %d is too long for the counter strategy, the maximum is %d", length, usecase.MaxCounterShortCodeLength)
+		}
+		salt := config.GetString("shortcode.salt")
+		if salt == "" {
+			log.Fatal("shortcode.salt must be configured to scramble counter codes")
+		}
+		return usecase.NewCounterShortCodeGenerator(sequenceRepository, salt, length)
+	case "hash":
+		return usecase.NewHashShortCodeGenerator(length)
+	default:
+		log.Fatalf("Unknown short code strategy: %s", strategy)
+		return nil
+	}
+}
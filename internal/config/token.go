@@ -0,0 +1,62 @@
+package config
+
+import (
+	"devshort-backend/internal/usecase"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type tokenKeyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+// NewTokenService loads the JWT keys listed in jwt.keys. Key files are
+// resolved relative to config.json. Startup fails without usable keys, as
+// tokens can not be issued or checked safely without them.
+func NewTokenService(config *viper.Viper, log *logrus.Logger) *usecase.TokenService {
+	var keyConfigs []tokenKeyConfig
+	if err := config.UnmarshalKey("jwt.keys", &keyConfigs); err != nil {
+		log.Fatalf("Failed to read jwt.keys: %v", err)
+	}
+	if len(keyConfigs) == 0 {
+		log.Fatal("jwt.keys must configure at least one key")
+	}
+
+	keys := make([]*usecase.TokenKey, 0, len(keyConfigs))
+	for _, keyConfig := range keyConfigs {
+		privatePem := readKeyFile(config, log, keyConfig.PrivateKeyFile)
+		publicPem := readKeyFile(config, log, keyConfig.PublicKeyFile)
+		if privatePem == nil && publicPem == nil {
+			log.Fatalf("jwt key %s has no key file", keyConfig.Kid)
+		}
+
+		key, err := usecase.NewTokenKey(keyConfig.Kid, keyConfig.Algorithm, privatePem, publicPem)
+		if err != nil {
+			log.Fatalf("Failed to load jwt key %s: %v", keyConfig.Kid, err)
+		}
+		keys = append(keys, key)
+	}
+
+	service, err := usecase.NewTokenService(config.GetString("jwt.issuer"), config.GetString("jwt.signing_kid"), keys)
+	if err != nil {
+		log.Fatalf("Failed to create token service: %v", err)
+	}
+	return service
+}
+
+func readKeyFile(config *viper.Viper, log *logrus.Logger, path string) []byte {
+	if path == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(configPath(config, path))
+	if err != nil {
+		log.Fatalf("Failed to read jwt key file: %v", err)
+	}
+	return content
+}
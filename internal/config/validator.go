@@ -1,10 +1,45 @@
 package config
 
 import (
+	"devshort-backend/internal/usecase"
+	"reflect"
+	"regexp"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
 func NewValidator(viper *viper.Viper) *validator.Validate {
-	return validator.New()
+	validate := validator.New()
+
+	// report fields by the names clients send them with
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	aliasPolicy := NewAliasPolicy(viper)
+	_ = validate.RegisterValidation("alias", func(fl validator.FieldLevel) bool {
+		return aliasPolicy.Check(fl.Field().String()) == nil
+	})
+
+	return validate
+}
+
+func NewAliasPolicy(viper *viper.Viper) *usecase.AliasPolicy {
+	viper.SetDefault("alias.min_length", 3)
+	viper.SetDefault("alias.max_length", 50)
+	viper.SetDefault("alias.pattern", "^[A-Za-z0-9_-]+$")
+
+	return usecase.NewAliasPolicy(
+		viper.GetInt("alias.min_length"),
+		viper.GetInt("alias.max_length"),
+		regexp.MustCompile(viper.GetString("alias.pattern")),
+		viper.GetBool("alias.case_sensitive"),
+		viper.GetStringSlice("alias.reserved"),
+	)
 }
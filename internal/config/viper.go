@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/viper"
 )
@@ -23,3 +24,12 @@ func NewViper() *viper.Viper {
 
 	return config
 }
+
+// configPath resolves path relative to the directory of the config file, so
+// files referenced by config.json are found wherever the binary runs from
+func configPath(config *viper.Viper, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(config.ConfigFileUsed()), path)
+}
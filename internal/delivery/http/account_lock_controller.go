@@ -0,0 +1,36 @@
+package http
+
+import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AccountLockController struct {
+	UseCase *usecase.AccountLockUseCase
+	Log     *logrus.Logger
+}
+
+func NewAccountLockController(useCase *usecase.AccountLockUseCase, log *logrus.Logger) *AccountLockController {
+	return &AccountLockController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *AccountLockController) Unlock(ctx *fiber.Ctx) error {
+	request := new(model.UnlockAccountRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := c.UseCase.Unlock(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to unlock account")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ApiKeyController struct {
+	UseCase *usecase.ApiKeyUseCase
+	Log     *logrus.Logger
+}
+
+func NewApiKeyController(useCase *usecase.ApiKeyUseCase, log *logrus.Logger) *ApiKeyController {
+	return &ApiKeyController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *ApiKeyController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateApiKeyRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to create api key")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ApiKeyResponse]{Data: response})
+}
+
+func (c *ApiKeyController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListApiKeyRequest{UserId: auth.ID}
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to list api keys")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.ApiKeyResponse]{Data: responses})
+}
+
+func (c *ApiKeyController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteApiKeyRequest{
+		ID:     ctx.Params("keyId"),
+		UserId: auth.ID,
+	}
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete api key")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
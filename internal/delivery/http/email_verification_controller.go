@@ -0,0 +1,50 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type EmailVerificationController struct {
+	UseCase *usecase.EmailVerificationUseCase
+	Log     *logrus.Logger
+}
+
+func NewEmailVerificationController(useCase *usecase.EmailVerificationUseCase, log *logrus.Logger) *EmailVerificationController {
+	return &EmailVerificationController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *EmailVerificationController) Verify(ctx *fiber.Ctx) error {
+	request := new(model.VerifyEmailRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	response, err := c.UseCase.Verify(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to verify email")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+func (c *EmailVerificationController) Resend(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ResendVerificationRequest{UserId: auth.ID}
+	if err := c.UseCase.Resend(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to resend verification email")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
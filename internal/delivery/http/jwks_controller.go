@@ -0,0 +1,25 @@
+package http
+
+import (
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type JwksController struct {
+	TokenService *usecase.TokenService
+	Log          *logrus.Logger
+}
+
+func NewJwksController(tokenService *usecase.TokenService, log *logrus.Logger) *JwksController {
+	return &JwksController{
+		TokenService: tokenService,
+		Log:          log,
+	}
+}
+
+func (c *JwksController) Get(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(c.TokenService.Jwks())
+}
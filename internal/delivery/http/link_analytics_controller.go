@@ -0,0 +1,65 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type LinkAnalyticsController struct {
+	UseCase *usecase.LinkAnalyticsUseCase
+	Log     *logrus.Logger
+}
+
+func NewLinkAnalyticsController(useCase *usecase.LinkAnalyticsUseCase, log *logrus.Logger) *LinkAnalyticsController {
+	return &LinkAnalyticsController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *LinkAnalyticsController) TimeSeries(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetTimeSeriesRequest{
+		ID:       ctx.Params("linkId"),
+		UserId:   auth.ID,
+		Interval: ctx.Query("interval", "day"),
+		Timezone: ctx.Query("tz", "UTC"),
+		From:     ctx.Query("from"),
+		To:       ctx.Query("to"),
+	}
+
+	response, err := c.UseCase.TimeSeries(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting link time series")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.TimeSeriesResponse]{Data: response})
+}
+
+func (c *LinkAnalyticsController) Breakdown(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetBreakdownRequest{
+		ID:        ctx.Params("linkId"),
+		UserId:    auth.ID,
+		Dimension: ctx.Query("by"),
+		Timezone:  ctx.Query("tz", "UTC"),
+		From:      ctx.Query("from"),
+		To:        ctx.Query("to"),
+		Limit:     ctx.QueryInt("limit", 10),
+	}
+
+	response, err := c.UseCase.Breakdown(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting link breakdown")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.BreakdownResponse]{Data: response})
+}
@@ -4,6 +4,9 @@ import (
 	"devshort-backend/internal/delivery/http/middleware"
 	"devshort-backend/internal/model"
 	"devshort-backend/internal/usecase"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -34,7 +37,7 @@ func (c *LinkController) Create(ctx *fiber.Ctx) error {
 	response, err := c.UseCase.Create(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Error("error creating link")
-		return fiber.ErrInternalServerError
+		return err
 	}
 
 	return ctx.JSON(model.WebResponse[*model.LinkResponse]{Data: response})
@@ -51,7 +54,7 @@ func (c *LinkController) Get(ctx *fiber.Ctx) error {
 	response, err := c.UseCase.Get(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Error("error getting link")
-		return fiber.ErrInternalServerError
+		return err
 	}
 
 	return ctx.JSON(model.WebResponse[*model.LinkResponse]{Data: response})
@@ -61,16 +64,62 @@ func (c *LinkController) List(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 
 	request := &model.ListLinkRequest{
-		UserId: auth.ID,
+		UserId:      auth.ID,
+		Page:        ctx.QueryInt("page", 1),
+		Size:        ctx.QueryInt("size", 10),
+		Sort:        ctx.Query("sort", "created_at"),
+		Order:       strings.ToLower(ctx.Query("order", "desc")),
+		Search:      ctx.Query("search"),
+		CreatedFrom: int64(ctx.QueryInt("created_from", 0)),
+		CreatedTo:   int64(ctx.QueryInt("created_to", 0)),
+		Cursor:      ctx.Query("cursor"),
+		TagMode:     strings.ToLower(ctx.Query("tag_mode", model.TagModeAny)),
+	}
+
+	// tags come as repeated tag params, each may hold several comma separated names
+	for _, tags := range ctx.Context().QueryArgs().PeekMulti("tag") {
+		request.Tags = append(request.Tags, strings.Split(string(tags), ",")...)
+	}
+
+	if isActive := ctx.Query("is_active"); isActive != "" {
+		value, err := strconv.ParseBool(isActive)
+		if err != nil {
+			c.Log.WithError(err).Error("error parsing is_active filter")
+			return fiber.ErrBadRequest
+		}
+		request.IsActive = &value
 	}
 
-	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if request.Cursor != "" || ctx.Query("mode") == "cursor" {
+		responses, cursor, err := c.UseCase.ListByCursor(ctx.UserContext(), request)
+		if err != nil {
+			c.Log.WithError(err).Error("failed to list links by cursor")
+			return err
+		}
+
+		return ctx.JSON(model.WebResponse[[]model.LinkResponse]{
+			Data:   responses,
+			Cursor: cursor,
+		})
+	}
+
+	responses, total, err := c.UseCase.List(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Error("failed to list links")
 		return err
 	}
 
-	return ctx.JSON(model.WebResponse[[]model.LinkResponse]{Data: responses})
+	paging := &model.PageMetadata{
+		Page:      request.Page,
+		Size:      request.Size,
+		TotalItem: total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(request.Size))),
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.LinkResponse]{
+		Data:   responses,
+		Paging: paging,
+	})
 }
 
 func (c *LinkController) Update(ctx *fiber.Ctx) error {
@@ -88,7 +137,7 @@ func (c *LinkController) Update(ctx *fiber.Ctx) error {
 	response, err := c.UseCase.Update(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Error("error updating link")
-		return fiber.ErrInternalServerError
+		return err
 	}
 
 	return ctx.JSON(model.WebResponse[*model.LinkResponse]{Data: response})
@@ -105,7 +154,7 @@ func (c *LinkController) Delete(ctx *fiber.Ctx) error {
 
 	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
 		c.Log.WithError(err).Error("error deleting link")
-		return fiber.ErrInternalServerError
+		return err
 	}
 
 	return ctx.JSON(model.WebResponse[bool]{Data: true})
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type LinkStatsController struct {
+	UseCase *usecase.LinkStatsUseCase
+	Log     *logrus.Logger
+}
+
+func NewLinkStatsController(useCase *usecase.LinkStatsUseCase, log *logrus.Logger) *LinkStatsController {
+	return &LinkStatsController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *LinkStatsController) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetLinkStatsRequest{
+		ID:     ctx.Params("linkId"),
+		UserId: auth.ID,
+		Days:   ctx.QueryInt("days", 30),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Error("error getting link stats")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.LinkStatsResponse]{Data: response})
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type MfaController struct {
+	UseCase *usecase.MfaUseCase
+	Log     *logrus.Logger
+}
+
+func NewMfaController(useCase *usecase.MfaUseCase, log *logrus.Logger) *MfaController {
+	return &MfaController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *MfaController) Enroll(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.EnrollTotpRequest{UserId: auth.ID}
+	response, err := c.UseCase.Enroll(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to enroll totp")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.TotpEnrollmentResponse]{Data: response})
+}
+
+func (c *MfaController) Confirm(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.ConfirmTotpRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	response, err := c.UseCase.Confirm(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to confirm totp")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.RecoveryCodesResponse]{Data: response})
+}
+
+func (c *MfaController) Disable(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.DisableTotpRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserId = auth.ID
+	if err := c.UseCase.Disable(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to disable totp")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errInvalidApiKey = usecase.NewAppError(fiber.StatusUnauthorized, "invalid_api_key", "Invalid or expired api key")
+
+// NewApiKeyAuth authenticates requests carrying an API key, either in the
+// X-API-Key header or as a bearer token. Other requests are left to the next
+// auth middleware.
+func NewApiKeyAuth(apiKeyUseCase *usecase.ApiKeyUseCase) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get("X-API-Key")
+		if key == "" {
+			bearer := strings.TrimPrefix(ctx.Get("Authorization"), "Bearer ")
+			if strings.HasPrefix(bearer, usecase.ApiKeyPrefix) {
+				key = bearer
+			}
+		}
+		if key == "" {
+			return ctx.Next()
+		}
+
+		auth, err := apiKeyUseCase.Verify(ctx.UserContext(), &model.VerifyApiKeyRequest{Key: key})
+		if err != nil {
+			apiKeyUseCase.Log.Warnf("Failed to verify api key : %+v", err)
+			return errInvalidApiKey
+		}
+
+		ctx.Locals("auth", auth)
+		return ctx.Next()
+	}
+}
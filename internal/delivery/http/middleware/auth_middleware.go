@@ -2,44 +2,46 @@ package middleware
 
 import (
 	"devshort-backend/internal/model"
-	"os"
+	"devshort-backend/internal/usecase"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+var (
+	errMissingToken = usecase.NewAppError(fiber.StatusUnauthorized, "missing_token", "Authorization is required")
+	errTokenFormat  = usecase.NewAppError(fiber.StatusUnauthorized, "invalid_token_format", "Invalid token format")
+	errInvalidToken = usecase.NewAppError(fiber.StatusUnauthorized, "invalid_token", "Invalid or expired token")
+	errSessionEnded = usecase.NewAppError(fiber.StatusUnauthorized, "session_ended", "Session has ended")
+)
 
-func NewAuth() fiber.Handler {
+func NewAuth(userUseCase *usecase.UserUseCase, sessionUseCase *usecase.SessionUseCase) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
+		// already authenticated by an API key
+		if ctx.Locals("auth") != nil {
+			return ctx.Next()
+		}
+
 		authHeader := ctx.Get("Authorization")
 		if authHeader == "" {
-			return fiber.ErrUnauthorized
+			return errMissingToken
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenStr == authHeader {
-			return fiber.NewError(fiber.StatusUnauthorized, "Invalid token format")
+			return errTokenFormat
 		}
 
-		// parse & verifikasi JWT
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.ErrUnauthorized
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+		auth, err := userUseCase.Verify(ctx.UserContext(), &model.VerifyUserRequest{Token: tokenStr})
+		if err != nil {
+			userUseCase.Log.Warnf("Failed to verify token : %+v", err)
+			return errInvalidToken
 		}
 
-		// ambil claims
-		claims := token.Claims.(jwt.MapClaims)
-
-		auth := &model.Auth{
-			ID: claims["id"].(string),
+		if auth.SessionId != "" {
+			if err := sessionUseCase.Touch(ctx.UserContext(), auth); err != nil {
+				return errSessionEnded
+			}
 		}
 
 		ctx.Locals("auth", auth)
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewRateLimit limits requests to limit. Authenticated requests are counted
+// per API key or per user, the others per client IP. Every response carries
+// the RateLimit-* headers; once the limit is used up the error handler turns
+// the *usecase.AppError into a 429 with Retry-After.
+func NewRateLimit(rateLimiter *usecase.RateLimiter, limit *usecase.RateLimit) fiber.Handler {
+	policy := fmt.Sprintf("%d;w=%d", limit.Limit, int(limit.Window.Seconds()))
+
+	return func(ctx *fiber.Ctx) error {
+		result, err := rateLimiter.Take(ctx.UserContext(), limit, rateLimitSubject(ctx))
+		if result != nil {
+			ctx.Set("RateLimit-Policy", policy)
+			ctx.Set("RateLimit-Limit", strconv.Itoa(limit.Limit))
+			ctx.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			ctx.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		}
+		if err != nil {
+			return err
+		}
+		return ctx.Next()
+	}
+}
+
+func rateLimitSubject(ctx *fiber.Ctx) string {
+	if auth, ok := ctx.Locals("auth").(*model.Auth); ok {
+		if auth.ApiKeyId != "" {
+			return "key:" + auth.ApiKeyId
+		}
+		return "user:" + auth.ID
+	}
+	return "ip:" + ctx.IP()
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"devshort-backend/internal/usecase"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope rejects credentials missing any of scopes with a 403 the
+// client can recognize by its insufficient_scope code
+func RequireScope(scopes ...string) fiber.Handler {
+	required := strings.Join(scopes, " ")
+
+	return func(ctx *fiber.Ctx) error {
+		auth := GetUser(ctx)
+		for _, scope := range scopes {
+			if !auth.HasScope(scope) {
+				ctx.Set(fiber.HeaderWWWAuthenticate, `Bearer error="insufficient_scope", scope="`+required+`"`)
+				return usecase.NewAppError(fiber.StatusForbidden, "insufficient_scope", "Missing required scope "+scope)
+			}
+		}
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PasswordResetController struct {
+	UseCase *usecase.PasswordResetUseCase
+	Log     *logrus.Logger
+}
+
+func NewPasswordResetController(useCase *usecase.PasswordResetUseCase, log *logrus.Logger) *PasswordResetController {
+	return &PasswordResetController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *PasswordResetController) Request(ctx *fiber.Ctx) error {
+	request := new(model.RequestPasswordResetRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := c.UseCase.Request(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to request password reset")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
+
+func (c *PasswordResetController) Confirm(ctx *fiber.Ctx) error {
+	request := new(model.ConfirmPasswordResetRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := c.UseCase.Confirm(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to confirm password reset")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type RedirectController struct {
+	UseCase *usecase.RedirectUseCase
+	Log     *logrus.Logger
+	// CountryHeader is set by the CDN or proxy in front of the service
+	CountryHeader string
+	// ExpiredFallbackUrl receives visitors of expired links, when empty they get a 410
+	ExpiredFallbackUrl string
+}
+
+func NewRedirectController(useCase *usecase.RedirectUseCase, log *logrus.Logger, countryHeader string, expiredFallbackUrl string) *RedirectController {
+	return &RedirectController{
+		UseCase:            useCase,
+		Log:                log,
+		CountryHeader:      countryHeader,
+		ExpiredFallbackUrl: expiredFallbackUrl,
+	}
+}
+
+// unlockCookie holds the token that lets a visitor through a password
+// protected link, scoped to the short url path it was issued for
+const unlockCookie = "devshort_unlock"
+
+func (c *RedirectController) Redirect(ctx *fiber.Ctx) error {
+	request := c.resolveRequest(ctx)
+	request.UnlockToken = ctx.Cookies(unlockCookie)
+
+	response, err := c.UseCase.Resolve(ctx.UserContext(), request)
+	if errors.Is(err, usecase.ErrLinkExpired) && c.ExpiredFallbackUrl != "" {
+		return ctx.Redirect(c.ExpiredFallbackUrl, fiber.StatusFound)
+	}
+	if errors.Is(err, usecase.ErrLinkLocked) && c.acceptsHtml(ctx) {
+		return c.renderUnlockPage(ctx, fiber.StatusUnauthorized, "")
+	}
+	if err != nil {
+		c.Log.WithError(err).Warn("error resolving short url")
+		return err
+	}
+
+	return ctx.Redirect(response.LongUrl, response.RedirectType)
+}
+
+// Unlock takes the password of a protected link, either from the HTML form,
+// which is answered with a redirect, or as JSON, which is answered with the
+// token and long url. Both set the unlock cookie.
+func (c *RedirectController) Unlock(ctx *fiber.Ctx) error {
+	request := new(model.UnlockLinkRequest)
+	form := !ctx.Is("json")
+
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithError(err).Warn("error parsing request body")
+		return fiber.ErrBadRequest
+	}
+	request.ResolveLinkRequest = *c.resolveRequest(ctx)
+
+	response, err := c.UseCase.Unlock(ctx.UserContext(), request)
+	if errors.Is(err, usecase.ErrLinkExpired) && c.ExpiredFallbackUrl != "" && form {
+		return ctx.Redirect(c.ExpiredFallbackUrl, fiber.StatusSeeOther)
+	}
+	if err != nil {
+		c.Log.WithError(err).Warn("error unlocking short url")
+		if form {
+			appErr := usecase.ToAppError(err)
+			return c.renderUnlockPage(ctx, appErr.Status, appErr.Message)
+		}
+		return err
+	}
+
+	ctx.Cookie(&fiber.Cookie{
+		Name:     unlockCookie,
+		Value:    response.Token,
+		Path:     "/" + ctx.Params("code"),
+		Expires:  time.UnixMilli(response.ExpiresAt),
+		HTTPOnly: true,
+		Secure:   ctx.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	if form {
+		return ctx.Redirect(response.LongUrl, fiber.StatusSeeOther)
+	}
+	return ctx.JSON(model.WebResponse[*model.UnlockLinkResponse]{Data: response})
+}
+
+func (c *RedirectController) resolveRequest(ctx *fiber.Ctx) *model.ResolveLinkRequest {
+	request := &model.ResolveLinkRequest{
+		ShortUrl:       ctx.Params("code"),
+		Referrer:       ctx.Get(fiber.HeaderReferer),
+		UserAgent:      ctx.Get(fiber.HeaderUserAgent),
+		IpAddress:      ctx.IP(),
+		AcceptLanguage: ctx.Get(fiber.HeaderAcceptLanguage),
+	}
+	if c.CountryHeader != "" {
+		request.Country = ctx.Get(c.CountryHeader)
+	}
+	return request
+}
+
+func (c *RedirectController) acceptsHtml(ctx *fiber.Ctx) bool {
+	return ctx.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) == fiber.MIMETextHTML
+}
+
+func (c *RedirectController) renderUnlockPage(ctx *fiber.Ctx, status int, message string) error {
+	ctx.Status(status).Type("html", "utf-8")
+	return unlockPage.Execute(ctx, unlockPageData{
+		Code:  ctx.Params("code"),
+		Error: message,
+	})
+}
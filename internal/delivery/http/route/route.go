@@ -2,15 +2,33 @@ package route
 
 import (
 	"devshort-backend/internal/delivery/http"
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type RouteConfig struct {
-	App               *fiber.App
-	UserController    *http.UserController
-	LinkController		*http.LinkController
-	AuthMiddleware    fiber.Handler
+	App                         *fiber.App
+	UserController              *http.UserController
+	LinkController              *http.LinkController
+	RedirectController          *http.RedirectController
+	LinkStatsController         *http.LinkStatsController
+	LinkAnalyticsController     *http.LinkAnalyticsController
+	SessionController           *http.SessionController
+	ApiKeyController            *http.ApiKeyController
+	JwksController              *http.JwksController
+	PasswordResetController     *http.PasswordResetController
+	EmailVerificationController *http.EmailVerificationController
+	MfaController               *http.MfaController
+	AccountLockController       *http.AccountLockController
+	TagController               *http.TagController
+	ApiKeyMiddleware            fiber.Handler
+	AuthMiddleware              fiber.Handler
+	GuestRateLimit              fiber.Handler
+	AuthRateLimit               fiber.Handler
+	AuthIpRateLimit             fiber.Handler
+	LinkCreateRateLimit         fiber.Handler
 }
 
 func (c *RouteConfig) Setup() {
@@ -19,19 +37,59 @@ func (c *RouteConfig) Setup() {
 }
 
 func (c *RouteConfig) SetupGuestRoute() {
-	c.App.Post("/api/users", c.UserController.Register)
-	c.App.Post("/api/users/_login", c.UserController.Login)
+	guest := c.GuestRateLimit
+	c.App.Post("/api/users", guest, c.UserController.Register)
+	c.App.Post("/api/users/_login", guest, c.UserController.Login)
+	c.App.Post("/api/users/_login/_mfa", guest, c.UserController.LoginMfa)
+	c.App.Post("/api/users/_refresh", guest, c.UserController.Refresh)
+	c.App.Post("/api/users/_password_reset", guest, c.PasswordResetController.Request)
+	c.App.Post("/api/users/_password_reset/_confirm", guest, c.PasswordResetController.Confirm)
+	c.App.Post("/api/users/_verify_email", guest, c.EmailVerificationController.Verify)
+	c.App.Post("/api/users/_unlock", guest, c.AccountLockController.Unlock)
+	c.App.Get("/.well-known/jwks.json", c.JwksController.Get)
+
+	// short codes live at the root, so these must stay the last guest routes.
+	// Redirects are the public traffic and stay unlimited.
+	c.App.Get("/:code", c.RedirectController.Redirect)
+	c.App.Post("/:code/unlock", guest, c.RedirectController.Unlock)
 }
 
 func (c *RouteConfig) SetupAuthRoute() {
+	// counted per client IP before authentication, so requests with bad
+	// tokens or keys are limited too
+	c.App.Use(c.AuthIpRateLimit)
+	c.App.Use(c.ApiKeyMiddleware)
 	c.App.Use(c.AuthMiddleware)
-	c.App.Delete("/api/users", c.UserController.Logout)
-	c.App.Patch("/api/users/_current", c.UserController.Update)
-	c.App.Get("/api/users/_current", c.UserController.Current)
-
-	c.App.Get("/api/links", c.LinkController.List)
-	c.App.Post("/api/links", c.LinkController.Create)
-	c.App.Get("/api/links/:linkId", c.LinkController.Get)
-	c.App.Patch("/api/links/:linkId", c.LinkController.Update)
-	c.App.Delete("/api/links/:linkId", c.LinkController.Delete)
+	c.App.Use(c.AuthRateLimit)
+
+	account := middleware.RequireScope(model.ScopeAccount)
+	c.App.Delete("/api/users", account, c.UserController.Logout)
+	c.App.Get("/api/users/_current/sessions", account, c.SessionController.List)
+	c.App.Delete("/api/users/_current/sessions", account, c.UserController.LogoutAll)
+	c.App.Delete("/api/users/_current/sessions/:sessionId", account, c.SessionController.Delete)
+	c.App.Post("/api/users/_current/mfa/totp", account, c.MfaController.Enroll)
+	c.App.Post("/api/users/_current/mfa/totp/_confirm", account, c.MfaController.Confirm)
+	c.App.Delete("/api/users/_current/mfa/totp", account, c.MfaController.Disable)
+	c.App.Get("/api/users/_current/keys", account, c.ApiKeyController.List)
+	c.App.Post("/api/users/_current/keys", account, c.ApiKeyController.Create)
+	c.App.Delete("/api/users/_current/keys/:keyId", account, c.ApiKeyController.Delete)
+	c.App.Post("/api/users/_current/_verify_email", account, c.EmailVerificationController.Resend)
+	c.App.Patch("/api/users/_current", account, c.UserController.Update)
+	c.App.Get("/api/users/_current", account, c.UserController.Current)
+
+	linksRead := middleware.RequireScope(model.ScopeLinksRead)
+	linksWrite := middleware.RequireScope(model.ScopeLinksWrite)
+	statsRead := middleware.RequireScope(model.ScopeStatsRead)
+	c.App.Get("/api/links", linksRead, c.LinkController.List)
+	c.App.Post("/api/links", linksWrite, c.LinkCreateRateLimit, c.LinkController.Create)
+	c.App.Get("/api/links/:linkId", linksRead, c.LinkController.Get)
+	c.App.Patch("/api/links/:linkId", linksWrite, c.LinkController.Update)
+	c.App.Delete("/api/links/:linkId", linksWrite, c.LinkController.Delete)
+	c.App.Get("/api/tags", linksRead, c.TagController.List)
+	c.App.Post("/api/tags", linksWrite, c.TagController.Create)
+	c.App.Patch("/api/tags/:tagId", linksWrite, c.TagController.Update)
+	c.App.Delete("/api/tags/:tagId", linksWrite, c.TagController.Delete)
+	c.App.Get("/api/links/:linkId/stats", statsRead, c.LinkStatsController.Get)
+	c.App.Get("/api/links/:linkId/analytics/timeseries", statsRead, c.LinkAnalyticsController.TimeSeries)
+	c.App.Get("/api/links/:linkId/analytics/breakdown", statsRead, c.LinkAnalyticsController.Breakdown)
 }
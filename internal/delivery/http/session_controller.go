@@ -0,0 +1,55 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionController struct {
+	UseCase *usecase.SessionUseCase
+	Log     *logrus.Logger
+}
+
+func NewSessionController(useCase *usecase.SessionUseCase, log *logrus.Logger) *SessionController {
+	return &SessionController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *SessionController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListSessionRequest{
+		UserId:    auth.ID,
+		SessionId: auth.SessionId,
+	}
+
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to list sessions")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.SessionResponse]{Data: responses})
+}
+
+func (c *SessionController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteSessionRequest{
+		ID:     ctx.Params("sessionId"),
+		UserId: auth.ID,
+	}
+
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete session")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
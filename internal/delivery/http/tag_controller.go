@@ -0,0 +1,89 @@
+package http
+
+import (
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TagController struct {
+	UseCase *usecase.TagUseCase
+	Log     *logrus.Logger
+}
+
+func NewTagController(useCase *usecase.TagUseCase, log *logrus.Logger) *TagController {
+	return &TagController{
+		UseCase: useCase,
+		Log:     log,
+	}
+}
+
+func (c *TagController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateTagRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to create tag")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.TagResponse]{Data: response})
+}
+
+func (c *TagController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListTagRequest{UserId: auth.ID}
+	responses, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to list tags")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.TagResponse]{Data: responses})
+}
+
+func (c *TagController) Update(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateTagRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+	request.ID = ctx.Params("tagId")
+	request.UserId = auth.ID
+
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to update tag")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.TagResponse]{Data: response})
+}
+
+func (c *TagController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteTagRequest{
+		ID:     ctx.Params("tagId"),
+		UserId: auth.ID,
+	}
+	if err := c.UseCase.Delete(ctx.UserContext(), request); err != nil {
+		c.Log.WithError(err).Warnf("Failed to delete tag")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: true})
+}
@@ -0,0 +1,31 @@
+package http
+
+import "html/template"
+
+// unlockPage is the password form browsers get for protected links
+var unlockPage = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+</head>
+<body>
+<main>
+<h1>This link is password protected</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/{{.Code}}/unlock">
+<label for="password">Password</label>
+<input id="password" name="password" type="password" autocomplete="off" required autofocus>
+<button type="submit">Open link</button>
+</form>
+</main>
+</body>
+</html>
+`))
+
+type unlockPageData struct {
+	Code  string
+	Error string
+}
@@ -43,6 +43,8 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 		c.Log.Warnf("Failed to parse request body : %+v", err)
 		return fiber.ErrBadRequest
 	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IpAddress = ctx.IP()
 
 	response, err := c.UseCase.Login(ctx.UserContext(), request)
 	if err != nil {
@@ -53,6 +55,40 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
 }
 
+func (c *UserController) LoginMfa(ctx *fiber.Ctx) error {
+	request := new(model.LoginMfaRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+	request.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	request.IpAddress = ctx.IP()
+
+	response, err := c.UseCase.LoginMfa(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to login user with second factor : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+func (c *UserController) Refresh(ctx *fiber.Ctx) error {
+	request := new(model.RefreshTokenRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	response, err := c.UseCase.Refresh(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to refresh token : %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
 func (c *UserController) Current(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 
@@ -69,15 +105,36 @@ func (c *UserController) Current(ctx *fiber.Ctx) error {
 func (c *UserController) Logout(ctx *fiber.Ctx) error {
 	auth := middleware.GetUser(ctx)
 
-	request := &model.LogoutUserRequest{ID: auth.ID}
+	request := &model.LogoutUserRequest{
+		ID:        auth.ID,
+		SessionId: auth.SessionId,
+		TokenId:   auth.TokenId,
+		ExpiresAt: auth.ExpiresAt,
+	}
 	response, err := c.UseCase.Logout(ctx.UserContext(), request)
 	if err != nil {
 		c.Log.WithError(err).Warnf("Failed to logout user")
 		return err
 	}
 
-	// ⚠️ Dengan JWT stateless, logout cukup client-side (hapus token).
-	// Endpoint ini hanya return success supaya konsisten.
+	return ctx.JSON(model.WebResponse[bool]{Data: response})
+}
+
+func (c *UserController) LogoutAll(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.LogoutUserRequest{
+		ID:        auth.ID,
+		SessionId: auth.SessionId,
+		TokenId:   auth.TokenId,
+		ExpiresAt: auth.ExpiresAt,
+	}
+	response, err := c.UseCase.LogoutAll(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithError(err).Warnf("Failed to logout user everywhere")
+		return err
+	}
+
 	return ctx.JSON(model.WebResponse[bool]{Data: response})
 }
 
@@ -0,0 +1,46 @@
+package messaging
+
+import (
+	"context"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+)
+
+type ClickConsumer struct {
+	Log     *logrus.Logger
+	UseCase *usecase.LinkStatsUseCase
+}
+
+func NewClickConsumer(log *logrus.Logger, useCase *usecase.LinkStatsUseCase) *ClickConsumer {
+	return &ClickConsumer{
+		Log:     log,
+		UseCase: useCase,
+	}
+}
+
+func (c ClickConsumer) Consume(message *sarama.ConsumerMessage) error {
+	ClickEvent := new(model.ClickEvent)
+	if err := json.Unmarshal(message.Value, ClickEvent); err != nil {
+		c.Log.WithError(err).Error("error unmarshalling Click event")
+		return err
+	}
+
+	request := &model.RecordClickRequest{
+		Event:     ClickEvent,
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+	}
+
+	if err := c.UseCase.Record(context.Background(), request); err != nil {
+		c.Log.WithError(err).Error("error recording Click event")
+		return err
+	}
+
+	c.Log.Debugf("Recorded click of link %s from partition %d", ClickEvent.LinkId, message.Partition)
+	return nil
+}
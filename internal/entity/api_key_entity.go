@@ -0,0 +1,19 @@
+package entity
+
+// ApiKey is a long lived credential for scripts. Only the hash of the key is
+// stored; Prefix is the start of the key, kept so users can tell keys apart.
+type ApiKey struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	UserId     string `gorm:"column:user_id"`
+	Name       string `gorm:"column:name"`
+	Prefix     string `gorm:"column:prefix"`
+	KeyHash    string `gorm:"column:key_hash"`
+	Scopes     string `gorm:"column:scopes"`
+	ExpiresAt  *int64 `gorm:"column:expires_at"`
+	LastUsedAt *int64 `gorm:"column:last_used_at"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (k *ApiKey) TableName() string {
+	return "api_keys"
+}
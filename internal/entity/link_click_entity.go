@@ -0,0 +1,19 @@
+package entity
+
+// LinkClick is a single resolved visit, kept for analytics queries that need
+// more detail than the daily aggregates
+type LinkClick struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	LinkId    string `gorm:"column:link_id"`
+	ClickedAt int64  `gorm:"column:clicked_at"`
+	Referrer  string `gorm:"column:referrer"`
+	Device    string `gorm:"column:device"`
+	OS        string `gorm:"column:os"`
+	Browser   string `gorm:"column:browser"`
+	Country   string `gorm:"column:country"`
+	IpHash    string `gorm:"column:ip_hash"`
+}
+
+func (c *LinkClick) TableName() string {
+	return "link_clicks"
+}
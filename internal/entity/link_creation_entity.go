@@ -0,0 +1,13 @@
+package entity
+
+// LinkCreation counts the links a user created on a day (UTC). Deleting a
+// link does not take it back, so the daily quota can not be reused.
+type LinkCreation struct {
+	UserId string `gorm:"column:user_id;primaryKey"`
+	Day    string `gorm:"column:day;primaryKey"`
+	Links  int64  `gorm:"column:links"`
+}
+
+func (c *LinkCreation) TableName() string {
+	return "link_creations"
+}
@@ -1,15 +1,21 @@
 package entity
 
 type Link struct {
-	ID        string  `gorm:"column:id;primaryKey"`
-	UserId    string  `gorm:"column:user_id"`
-	Title     string  `gorm:"column:title"`
-	ShortUrl  string  `gorm:"column:short_url"`
-	LongUrl   string  `gorm:"column:long_url"`
-	IsActive  bool    `gorm:"column:is_active"`
-	CreatedAt int64   `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64   `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	User      User    `gorm:"foreignKey:user_id;references:id"`
+	ID           string  `gorm:"column:id;primaryKey"`
+	UserId       string  `gorm:"column:user_id"`
+	Title        string  `gorm:"column:title"`
+	ShortUrl     string  `gorm:"column:short_url"`
+	LongUrl      string  `gorm:"column:long_url"`
+	RedirectType int     `gorm:"column:redirect_type"`
+	Password     *string `gorm:"column:password"`
+	IsActive     bool    `gorm:"column:is_active"`
+	ExpiresAt    *int64  `gorm:"column:expires_at"`
+	MaxClicks    *int64  `gorm:"column:max_clicks"`
+	ClickCount   int64   `gorm:"column:click_count"`
+	CreatedAt    int64   `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt    int64   `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User         User    `gorm:"foreignKey:user_id;references:id"`
+	Tags         []Tag   `gorm:"many2many:link_tags"`
 }
 
 func (a *Link) TableName() string {
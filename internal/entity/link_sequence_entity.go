@@ -0,0 +1,11 @@
+package entity
+
+// LinkSequence is a row in the counter backing counter-based short codes
+type LinkSequence struct {
+	ID        int64 `gorm:"column:id;primaryKey;autoIncrement"`
+	CreatedAt int64 `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (s *LinkSequence) TableName() string {
+	return "link_sequences"
+}
@@ -0,0 +1,64 @@
+package entity
+
+// ClickOffset marks a consumed click message, so redeliveries are skipped
+type ClickOffset struct {
+	Topic       string `gorm:"column:topic;primaryKey"`
+	Partition   int32  `gorm:"column:partition_id;primaryKey"`
+	Offset      int64  `gorm:"column:offset_id;primaryKey"`
+	ProcessedAt int64  `gorm:"column:processed_at;autoCreateTime:milli"`
+}
+
+func (c *ClickOffset) TableName() string {
+	return "click_offsets"
+}
+
+type LinkDailyStat struct {
+	LinkId         string `gorm:"column:link_id;primaryKey"`
+	Day            string `gorm:"column:day;primaryKey"`
+	Clicks         int64  `gorm:"column:clicks"`
+	UniqueVisitors int64  `gorm:"column:unique_visitors"`
+}
+
+func (s *LinkDailyStat) TableName() string {
+	return "link_daily_stats"
+}
+
+type LinkVisitor struct {
+	LinkId string `gorm:"column:link_id;primaryKey"`
+	Day    string `gorm:"column:day;primaryKey"`
+	IpHash string `gorm:"column:ip_hash;primaryKey"`
+}
+
+func (v *LinkVisitor) TableName() string {
+	return "link_visitors"
+}
+
+type LinkReferrerStat struct {
+	LinkId   string `gorm:"column:link_id;primaryKey"`
+	Referrer string `gorm:"column:referrer;primaryKey"`
+	Clicks   int64  `gorm:"column:clicks"`
+}
+
+func (s *LinkReferrerStat) TableName() string {
+	return "link_referrer_stats"
+}
+
+type LinkBrowserStat struct {
+	LinkId  string `gorm:"column:link_id;primaryKey"`
+	Browser string `gorm:"column:browser;primaryKey"`
+	Clicks  int64  `gorm:"column:clicks"`
+}
+
+func (s *LinkBrowserStat) TableName() string {
+	return "link_browser_stats"
+}
+
+type LinkCountryStat struct {
+	LinkId  string `gorm:"column:link_id;primaryKey"`
+	Country string `gorm:"column:country;primaryKey"`
+	Clicks  int64  `gorm:"column:clicks"`
+}
+
+func (s *LinkCountryStat) TableName() string {
+	return "link_country_stats"
+}
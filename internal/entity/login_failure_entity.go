@@ -0,0 +1,13 @@
+package entity
+
+// LoginFailure counts failed logins for a subject, either a user id or a
+// hashed client IP
+type LoginFailure struct {
+	Subject      string `gorm:"column:subject;primaryKey"`
+	Failures     int    `gorm:"column:failures"`
+	LastFailedAt int64  `gorm:"column:last_failed_at"`
+}
+
+func (l *LoginFailure) TableName() string {
+	return "login_failures"
+}
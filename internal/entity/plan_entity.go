@@ -0,0 +1,17 @@
+package entity
+
+// DefaultPlanId is the plan new users start on
+const DefaultPlanId = "free"
+
+// Plan caps how many links its users may own and create per day, zero means
+// no cap
+type Plan struct {
+	ID             string `gorm:"column:id;primaryKey"`
+	Name           string `gorm:"column:name"`
+	MaxLinks       int    `gorm:"column:max_links"`
+	MaxLinksPerDay int    `gorm:"column:max_links_per_day"`
+}
+
+func (p *Plan) TableName() string {
+	return "plans"
+}
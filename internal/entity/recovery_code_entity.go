@@ -0,0 +1,15 @@
+package entity
+
+// RecoveryCode is a single use code that stands in for a TOTP code when the
+// user lost their authenticator. Only the hash of the code is stored.
+type RecoveryCode struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	CodeHash  string `gorm:"column:code_hash"`
+	UsedAt    *int64 `gorm:"column:used_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
@@ -0,0 +1,18 @@
+package entity
+
+// RefreshToken is one link in a rotation chain. Every token issued from the
+// same login shares a FamilyId, so replaying a used token can revoke them all.
+type RefreshToken struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	FamilyId  string `gorm:"column:family_id"`
+	TokenHash string `gorm:"column:token_hash"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	UsedAt    *int64 `gorm:"column:used_at"`
+	RevokedAt *int64 `gorm:"column:revoked_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,16 @@
+package entity
+
+// Session is one login of a user on some device. Its id is the family id of
+// the refresh tokens and the sid claim of the access tokens issued for it.
+type Session struct {
+	ID         string `gorm:"column:id;primaryKey"`
+	UserId     string `gorm:"column:user_id"`
+	UserAgent  string `gorm:"column:user_agent"`
+	IpHash     string `gorm:"column:ip_hash"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	LastSeenAt int64  `gorm:"column:last_seen_at"`
+}
+
+func (s *Session) TableName() string {
+	return "sessions"
+}
@@ -0,0 +1,14 @@
+package entity
+
+// Tag groups links of one user, names are unique per user
+type Tag struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Name      string `gorm:"column:name"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (t *Tag) TableName() string {
+	return "tags"
+}
@@ -0,0 +1,19 @@
+package entity
+
+// TokenRevocation rejects access tokens before they expire on their own. A row
+// either revokes the token with Jti, every token of the session SessionId, or
+// every token of UserId issued before IssuedBefore. Rows are useless once ExpiresAt passes, as the tokens they
+// cover have expired by then.
+type TokenRevocation struct {
+	ID           string  `gorm:"column:id;primaryKey"`
+	Jti          *string `gorm:"column:jti"`
+	UserId       string  `gorm:"column:user_id"`
+	SessionId    *string `gorm:"column:session_id"`
+	IssuedBefore *int64  `gorm:"column:issued_before"`
+	ExpiresAt    int64   `gorm:"column:expires_at"`
+	CreatedAt    int64   `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *TokenRevocation) TableName() string {
+	return "token_revocations"
+}
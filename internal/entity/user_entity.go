@@ -2,13 +2,25 @@ package entity
 
 // User is a struct that represents a user entity
 type User struct {
-	ID        string    `gorm:"column:id;primaryKey"`
-	Password  string    `gorm:"column:password"`
-	Name      string    `gorm:"column:name"`
-	Token     string    `gorm:"column:token"`
-	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	Links     []Link    `gorm:"foreignKey:user_id;references:id"`
+	ID       string  `gorm:"column:id;primaryKey"`
+	Password string  `gorm:"column:password"`
+	Name     string  `gorm:"column:name"`
+	Email    *string `gorm:"column:email"`
+	// VerifiedAt is when Email was confirmed, nil until then and after it changes
+	VerifiedAt *int64 `gorm:"column:verified_at"`
+	// TotpSecret is set on enrollment, two factor login is on once TotpEnabledAt is set
+	TotpSecret    *string `gorm:"column:totp_secret"`
+	TotpEnabledAt *int64  `gorm:"column:totp_enabled_at"`
+	// TotpLastStep is the last accepted time step, so a code can not be replayed
+	TotpLastStep int64 `gorm:"column:totp_last_step"`
+	// LockedUntil blocks logins after too many failures
+	LockedUntil *int64 `gorm:"column:locked_until"`
+	// PlanId is the Plan whose quotas apply to the user
+	PlanId    string `gorm:"column:plan_id"`
+	Token     string `gorm:"column:token"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Links     []Link `gorm:"foreignKey:user_id;references:id"`
 }
 
 func (u *User) TableName() string {
@@ -0,0 +1,24 @@
+package entity
+
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+	UserTokenMfaChallenge      = "mfa_challenge"
+	UserTokenAccountUnlock     = "account_unlock"
+)
+
+// UserToken is a single use token mailed to a user, such as a password reset
+// link. Only the hash of the token is stored.
+type UserToken struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	UserId    string `gorm:"column:user_id"`
+	Purpose   string `gorm:"column:purpose"`
+	TokenHash string `gorm:"column:token_hash"`
+	ExpiresAt int64  `gorm:"column:expires_at"`
+	UsedAt    *int64 `gorm:"column:used_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (u *UserToken) TableName() string {
+	return "user_tokens"
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileSender writes every mail to Dir as an .eml file instead of sending it
+type FileSender struct {
+	Dir  string
+	From string
+	Log  *logrus.Logger
+}
+
+func NewFileSender(dir string, from string, log *logrus.Logger) *FileSender {
+	return &FileSender{
+		Dir:  dir,
+		From: from,
+		Log:  log,
+	}
+}
+
+func (s *FileSender) Send(ctx context.Context, message *Message) error {
+	if !validHeader(message.To) || !validHeader(message.Subject) {
+		return ErrInvalidHeader
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("/", "_", "\\", "_").Replace(message.To)
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+	path := filepath.Join(s.Dir, name)
+	if err := os.WriteFile(path, render(s.From, message), 0o600); err != nil {
+		s.Log.WithError(err).Error("failed to write mail")
+		return err
+	}
+
+	s.Log.Infof("Mail %q to %s written to %s", message.Subject, message.To, path)
+	return nil
+}
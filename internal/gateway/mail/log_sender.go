@@ -0,0 +1,26 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSender prints mails to the log, for local development
+type LogSender struct {
+	Log *logrus.Logger
+}
+
+func NewLogSender(log *logrus.Logger) *LogSender {
+	return &LogSender{
+		Log: log,
+	}
+}
+
+func (s *LogSender) Send(ctx context.Context, message *Message) error {
+	s.Log.WithFields(logrus.Fields{
+		"to":      message.To,
+		"subject": message.Subject,
+	}).Info(message.Body)
+	return nil
+}
@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/smtp"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrInvalidHeader = errors.New("mail header contains a line break")
+
+type SmtpSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Log      *logrus.Logger
+}
+
+func NewSmtpSender(host string, port int, username string, password string, from string, log *logrus.Logger) *SmtpSender {
+	return &SmtpSender{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+		Log:      log,
+	}
+}
+
+func (s *SmtpSender) Send(ctx context.Context, message *Message) error {
+	if !validHeader(message.To) || !validHeader(message.Subject) {
+		return ErrInvalidHeader
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	address := fmt.Sprintf("%s:%d", s.Host, s.Port)
+	if err := smtp.SendMail(address, auth, s.From, []string{message.To}, render(s.From, message)); err != nil {
+		s.Log.WithError(err).Error("failed to send mail")
+		return err
+	}
+
+	s.Log.Debugf("Mail %q sent to %s", message.Subject, message.To)
+	return nil
+}
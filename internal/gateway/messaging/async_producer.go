@@ -0,0 +1,80 @@
+package messaging
+
+import (
+	"devshort-backend/internal/model"
+	"encoding/json"
+	"errors"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrProducerBusy = errors.New("producer buffer is full")
+
+// AsyncProducer hands events to sarama without waiting for broker acks. Send
+// never blocks: events wait in a queue of BufferSize messages that one
+// goroutine feeds into the producer, and are dropped only when it is full.
+// sarama's input channel is unbuffered, so it can not be the queue itself.
+type AsyncProducer[T model.Event] struct {
+	Producer sarama.AsyncProducer
+	Topic    string
+	Log      *logrus.Logger
+	queue    chan *sarama.ProducerMessage
+	done     chan struct{}
+}
+
+func NewAsyncProducer[T model.Event](producer sarama.AsyncProducer, topic string, log *logrus.Logger, bufferSize int) *AsyncProducer[T] {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	p := &AsyncProducer[T]{
+		Producer: producer,
+		Topic:    topic,
+		Log:      log,
+		queue:    make(chan *sarama.ProducerMessage, bufferSize),
+		done:     make(chan struct{}),
+	}
+	go p.forward()
+	return p
+}
+
+func (p *AsyncProducer[T]) GetTopic() *string {
+	return &p.Topic
+}
+
+func (p *AsyncProducer[T]) Send(event T) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		p.Log.WithError(err).Error("failed to marshal event")
+		return err
+	}
+
+	message := &sarama.ProducerMessage{
+		Topic: p.Topic,
+		Key:   sarama.StringEncoder(event.GetId()),
+		Value: sarama.ByteEncoder(value),
+	}
+
+	select {
+	case p.queue <- message:
+		return nil
+	default:
+		p.Log.Warnf("Dropping message for topic %s, producer buffer is full", p.Topic)
+		return ErrProducerBusy
+	}
+}
+
+// Close hands the queued events to sarama and closes the producer, Send must
+// not be called afterwards
+func (p *AsyncProducer[T]) Close() error {
+	close(p.queue)
+	<-p.done
+	return p.Producer.Close()
+}
+
+func (p *AsyncProducer[T]) forward() {
+	defer close(p.done)
+	for message := range p.queue {
+		p.Producer.Input() <- message
+	}
+}
@@ -0,0 +1,18 @@
+package messaging
+
+import (
+	"devshort-backend/internal/model"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+)
+
+type ClickProducer struct {
+	*AsyncProducer[*model.ClickEvent]
+}
+
+func NewClickProducer(producer sarama.AsyncProducer, log *logrus.Logger, bufferSize int) *ClickProducer {
+	return &ClickProducer{
+		AsyncProducer: NewAsyncProducer[*model.ClickEvent](producer, "clicks", log, bufferSize),
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+type ApiKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *int64   `json:"expires_at"`
+	LastUsedAt *int64   `json:"last_used_at"`
+	CreatedAt  int64    `json:"created_at"`
+	// Key is only returned once, when the key is created
+	Key string `json:"key,omitempty"`
+}
+
+type CreateApiKeyRequest struct {
+	UserId    string   `json:"-" validate:"required,max=100"`
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,oneof=links:read links:write stats:read"`
+	ExpiresAt *int64   `json:"expires_at" validate:"omitempty,gt=0"`
+}
+
+type ListApiKeyRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type DeleteApiKeyRequest struct {
+	ID     string `json:"-" validate:"required,max=100"`
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type VerifyApiKeyRequest struct {
+	Key string `validate:"required,max=100"`
+}
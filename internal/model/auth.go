@@ -1,6 +1,33 @@
 package model
 
+import "slices"
+
+// Scopes limit what a credential may do. API keys get the ones picked by
+// their owner, login tokens get LoginScopes.
+const (
+	ScopeLinksRead  = "links:read"
+	ScopeLinksWrite = "links:write"
+	ScopeStatsRead  = "stats:read"
+	// ScopeAccount manages the account itself, its sessions and API keys
+	ScopeAccount = "account"
+)
+
+var LoginScopes = []string{ScopeLinksRead, ScopeLinksWrite, ScopeStatsRead, ScopeAccount}
+
 type Auth struct {
 	// Login user id
 	ID string
+	// SessionId groups the tokens issued from one login
+	SessionId string
+	// TokenId is the jti of the access token, used to revoke it
+	TokenId   string
+	IssuedAt  int64
+	ExpiresAt int64
+	// ApiKeyId is set instead of the token fields for API key requests
+	ApiKeyId string
+	Scopes   []string
+}
+
+func (a *Auth) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
 }
@@ -0,0 +1,19 @@
+package model
+
+type ClickEvent struct {
+	ID             string `json:"id"`
+	LinkId         string `json:"link_id"`
+	ShortUrl       string `json:"short_url"`
+	Timestamp      int64  `json:"timestamp"`
+	Referrer       string `json:"referrer,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	IpHash         string `json:"ip_hash,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
+	Country        string `json:"country,omitempty"`
+}
+
+// GetId keys clicks by link, so all clicks of one link land in the same
+// partition and are consumed in order
+func (c *ClickEvent) GetId() string {
+	return c.LinkId
+}
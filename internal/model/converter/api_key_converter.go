@@ -0,0 +1,19 @@
+package converter
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"strings"
+)
+
+func ApiKeyToResponse(key *entity.ApiKey) *model.ApiKeyResponse {
+	return &model.ApiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Split(key.Scopes, ","),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -6,27 +6,48 @@ import (
 )
 
 func LinkToResponse(link *entity.Link) *model.LinkResponse {
+	tags := make([]string, len(link.Tags))
+	for i, tag := range link.Tags {
+		tags[i] = tag.Name
+	}
+
 	return &model.LinkResponse{
-		ID:       link.ID,
-		UserId:   link.UserId,
-		Title:    link.Title,
-		ShortUrl: link.ShortUrl,
-		LongUrl:  link.LongUrl,
-		IsActive: link.IsActive,
-		CreatedAt: link.CreatedAt,
-		UpdatedAt: link.UpdatedAt,
+		ID:           link.ID,
+		UserId:       link.UserId,
+		Title:        link.Title,
+		ShortUrl:     link.ShortUrl,
+		LongUrl:      link.LongUrl,
+		RedirectType: link.RedirectType,
+		HasPassword:  link.Password != nil,
+		IsActive:     link.IsActive,
+		ExpiresAt:    link.ExpiresAt,
+		MaxClicks:    link.MaxClicks,
+		ClickCount:   link.ClickCount,
+		Tags:         tags,
+		CreatedAt:    link.CreatedAt,
+		UpdatedAt:    link.UpdatedAt,
+	}
+}
+
+func LinkToRedirectResponse(link *entity.Link) *model.RedirectResponse {
+	return &model.RedirectResponse{
+		LongUrl:      link.LongUrl,
+		RedirectType: link.RedirectType,
 	}
 }
 
 func LinkToEvent(link *entity.Link) *model.LinkEvent {
 	return &model.LinkEvent{
-		ID:       link.ID,
-		UserId:   link.UserId,
-		Title:    link.Title,
-		ShortUrl: link.ShortUrl,
-		LongUrl:  link.LongUrl,
-		IsActive: link.IsActive,
-		CreatedAt: link.CreatedAt,
-		UpdatedAt: link.UpdatedAt,
+		ID:           link.ID,
+		UserId:       link.UserId,
+		Title:        link.Title,
+		ShortUrl:     link.ShortUrl,
+		LongUrl:      link.LongUrl,
+		RedirectType: link.RedirectType,
+		IsActive:     link.IsActive,
+		ExpiresAt:    link.ExpiresAt,
+		MaxClicks:    link.MaxClicks,
+		CreatedAt:    link.CreatedAt,
+		UpdatedAt:    link.UpdatedAt,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,35 @@
+package converter
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+)
+
+func DailyStatToResponse(stat *entity.LinkDailyStat) *model.DailyStatsResponse {
+	return &model.DailyStatsResponse{
+		Day:            stat.Day,
+		Clicks:         stat.Clicks,
+		UniqueVisitors: stat.UniqueVisitors,
+	}
+}
+
+func ReferrerStatToResponse(stat *entity.LinkReferrerStat) *model.CountStatsResponse {
+	return &model.CountStatsResponse{
+		Name:   stat.Referrer,
+		Clicks: stat.Clicks,
+	}
+}
+
+func BrowserStatToResponse(stat *entity.LinkBrowserStat) *model.CountStatsResponse {
+	return &model.CountStatsResponse{
+		Name:   stat.Browser,
+		Clicks: stat.Clicks,
+	}
+}
+
+func CountryStatToResponse(stat *entity.LinkCountryStat) *model.CountStatsResponse {
+	return &model.CountStatsResponse{
+		Name:   stat.Country,
+		Clicks: stat.Clicks,
+	}
+}
@@ -0,0 +1,16 @@
+package converter
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+)
+
+func SessionToResponse(session *entity.Session, currentSessionId string) *model.SessionResponse {
+	return &model.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		Current:    session.ID == currentSessionId,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+	}
+}
@@ -0,0 +1,15 @@
+package converter
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+)
+
+func TagToResponse(tag *entity.Tag) *model.TagResponse {
+	return &model.TagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	}
+}
@@ -6,12 +6,20 @@ import (
 )
 
 func UserToResponse(user *entity.User) *model.UserResponse {
-	return &model.UserResponse{
+	response := &model.UserResponse{
 		ID:        user.ID,
 		Name:      user.Name,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
+	if user.Email != nil {
+		response.Email = *user.Email
+	}
+	if user.VerifiedAt != nil {
+		response.VerifiedAt = *user.VerifiedAt
+	}
+	response.MfaEnabled = user.TotpEnabledAt != nil
+	return response
 }
 
 func UserToTokenResponse(user *entity.User) *model.UserResponse {
@@ -20,11 +28,16 @@ func UserToTokenResponse(user *entity.User) *model.UserResponse {
 	}
 }
 
-func UserToEvent(user *entity.User) *model.UserEvent {
-	return &model.UserEvent{
+func UserToEvent(user *entity.User, eventType string) *model.UserEvent {
+	event := &model.UserEvent{
 		ID:        user.ID,
+		Type:      eventType,
 		Name:      user.Name,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
+	if user.LockedUntil != nil {
+		event.LockedUntil = *user.LockedUntil
+	}
+	return event
 }
@@ -0,0 +1,18 @@
+package model
+
+// JwksResponse is a JSON Web Key Set (RFC 7517). It is served as is instead of
+// inside WebResponse, as JWT libraries expect this exact shape.
+type JwksResponse struct {
+	Keys []JwkResponse `json:"keys"`
+}
+
+type JwkResponse struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
@@ -0,0 +1,42 @@
+package model
+
+type TimeSeriesResponse struct {
+	Interval string                    `json:"interval"`
+	Timezone string                    `json:"timezone"`
+	From     string                    `json:"from"`
+	To       string                    `json:"to"`
+	Total    int64                     `json:"total"`
+	Points   []TimeSeriesPointResponse `json:"points"`
+}
+
+type TimeSeriesPointResponse struct {
+	Start  string `json:"start"`
+	Clicks int64  `json:"clicks"`
+}
+
+type BreakdownResponse struct {
+	Dimension string               `json:"dimension"`
+	From      string               `json:"from"`
+	To        string               `json:"to"`
+	Total     int64                `json:"total"`
+	Items     []CountStatsResponse `json:"items"`
+}
+
+type GetTimeSeriesRequest struct {
+	ID       string `json:"-" validate:"required,uuid"`
+	UserId   string `json:"-" validate:"required,max=100"`
+	Interval string `json:"-" validate:"required,oneof=hour day week"`
+	Timezone string `json:"-" validate:"required,timezone"`
+	From     string `json:"-"`
+	To       string `json:"-"`
+}
+
+type GetBreakdownRequest struct {
+	ID        string `json:"-" validate:"required,uuid"`
+	UserId    string `json:"-" validate:"required,max=100"`
+	Dimension string `json:"-" validate:"required,oneof=referrer device os browser country"`
+	Timezone  string `json:"-" validate:"required,timezone"`
+	From      string `json:"-"`
+	To        string `json:"-"`
+	Limit     int    `json:"-" validate:"min=1,max=100"`
+}
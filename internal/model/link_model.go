@@ -1,26 +1,75 @@
 package model
 
 type LinkResponse struct {
-	ID        string `json:"id"`
-	UserId    string `json:"user_id"`
-	Title     string `json:"title"`
-	ShortUrl  string `json:"short_url"`
-	LongUrl   string `json:"long_url"`
-	IsActive  bool   `json:"is_active"`
-	CreatedAt int64  `json:"created_at"`
-	UpdatedAt int64  `json:"updated_at"`
+	ID           string   `json:"id"`
+	UserId       string   `json:"user_id"`
+	Title        string   `json:"title"`
+	ShortUrl     string   `json:"short_url"`
+	LongUrl      string   `json:"long_url"`
+	RedirectType int      `json:"redirect_type"`
+	HasPassword  bool     `json:"has_password"`
+	IsActive     bool     `json:"is_active"`
+	ExpiresAt    *int64   `json:"expires_at"`
+	MaxClicks    *int64   `json:"max_clicks"`
+	ClickCount   int64    `json:"click_count"`
+	Tags         []string `json:"tags"`
+	CreatedAt    int64    `json:"created_at"`
+	UpdatedAt    int64    `json:"updated_at"`
+}
+
+type RedirectResponse struct {
+	LongUrl      string `json:"long_url"`
+	RedirectType int    `json:"redirect_type"`
+}
+
+type UnlockLinkResponse struct {
+	LongUrl      string `json:"long_url"`
+	RedirectType int    `json:"redirect_type"`
+	Token        string `json:"token"`
+	ExpiresAt    int64  `json:"expires_at"`
 }
 
 type ListLinkRequest struct {
-	UserId string `json:"-" validate:"required,max=100"`
+	UserId      string `json:"-" validate:"required,max=100"`
+	Page        int    `json:"-" validate:"min=1"`
+	Size        int    `json:"-" validate:"min=1,max=100"`
+	Sort        string `json:"-" validate:"oneof=created_at updated_at title short_url long_url"`
+	Order       string `json:"-" validate:"oneof=asc desc"`
+	Search      string `json:"-" validate:"max=100"`
+	IsActive    *bool  `json:"-"`
+	CreatedFrom int64  `json:"-" validate:"min=0"`
+	CreatedTo   int64  `json:"-" validate:"min=0"`
+	Cursor      string `json:"-" validate:"max=500"`
+	// Tags filters on tag names, TagMode tells whether links need any or all of them
+	Tags    []string `json:"-" validate:"max=20,dive,required,max=50"`
+	TagMode string   `json:"-" validate:"omitempty,oneof=any all"`
+}
+
+const (
+	TagModeAny = "any"
+	TagModeAll = "all"
+)
+
+// LinkCursor is the keyset position a cursor points at. Backward cursors page
+// towards newer links.
+type LinkCursor struct {
+	CreatedAt int64  `json:"c"`
+	ID        string `json:"i"`
+	Backward  bool   `json:"b,omitempty"`
 }
 
 type CreateLinkRequest struct {
-	UserId   string `json:"-" validate:"required,max=100"`
-	Title    string `json:"title" validate:"required,min=2,max=50"`
-	ShortUrl string `json:"short_url" validate:"required"`
-	LongUrl  string `json:"long_url" validate:"required"`
-	IsActive bool   `json:"is_active" validate:"required"`
+	UserId       string `json:"-" validate:"required,max=100"`
+	Title        string `json:"title" validate:"required,min=2,max=50"`
+	ShortUrl     string `json:"short_url" validate:"omitempty,alias"`
+	LongUrl      string `json:"long_url" validate:"required"`
+	RedirectType int    `json:"redirect_type" validate:"omitempty,oneof=301 302 307 308"`
+	IsActive     bool   `json:"is_active" validate:"required"`
+	ExpiresAt    *int64 `json:"expires_at" validate:"omitempty,gt=0"`
+	MaxClicks    *int64 `json:"max_clicks" validate:"omitempty,min=1"`
+	Password     string `json:"password" validate:"omitempty,max=72"`
+	// Tags are tag names, tags the user does not have yet are created
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50,excludesall=0x2C"`
 }
 
 type GetLinkRequest struct {
@@ -28,13 +77,36 @@ type GetLinkRequest struct {
 	UserId string `json:"-" validate:"required,max=100"`
 }
 
+type ResolveLinkRequest struct {
+	ShortUrl       string `json:"-" validate:"required,max=50"`
+	Referrer       string `json:"-"`
+	UserAgent      string `json:"-"`
+	IpAddress      string `json:"-"`
+	AcceptLanguage string `json:"-"`
+	Country        string `json:"-"`
+	// UnlockToken is the signed cookie handed out after a password unlock
+	UnlockToken string `json:"-"`
+}
+
+type UnlockLinkRequest struct {
+	ResolveLinkRequest
+	Password string `json:"password" form:"password" validate:"required,max=72"`
+}
+
 type UpdateLinkRequest struct {
-	ID       string `json:"-" validate:"required,uuid"`
-	UserId   string `json:"-" validate:"required,max=100"`
-	Title    string `json:"title" validate:"required,min=2,max=50"`
-	ShortUrl string `json:"short_url" validate:"required"`
-	LongUrl  string `json:"long_url" validate:"required"`
-	IsActive *bool  `json:"is_active" validate:"required"`
+	ID           string `json:"-" validate:"required,uuid"`
+	UserId       string `json:"-" validate:"required,max=100"`
+	Title        string `json:"title" validate:"required,min=2,max=50"`
+	ShortUrl     string `json:"short_url" validate:"required,max=50"`
+	LongUrl      string `json:"long_url" validate:"required"`
+	RedirectType int    `json:"redirect_type" validate:"omitempty,oneof=301 302 307 308"`
+	IsActive     *bool  `json:"is_active" validate:"required"`
+	ExpiresAt    *int64 `json:"expires_at" validate:"omitempty,gt=0"`
+	MaxClicks    *int64 `json:"max_clicks" validate:"omitempty,min=1"`
+	// Password keeps the current password when omitted and removes it when empty
+	Password *string `json:"password" validate:"omitempty,max=72"`
+	// Tags keeps the current tags when omitted and replaces them otherwise
+	Tags *[]string `json:"tags" validate:"omitempty,max=20,dive,required,max=50,excludesall=0x2C"`
 }
 
 type DeleteLinkRequest struct {
@@ -0,0 +1,35 @@
+package model
+
+type LinkStatsResponse struct {
+	LinkId         string               `json:"link_id"`
+	TotalClicks    int64                `json:"total_clicks"`
+	UniqueVisitors int64                `json:"unique_visitors"`
+	Daily          []DailyStatsResponse `json:"daily"`
+	TopReferrers   []CountStatsResponse `json:"top_referrers"`
+	Browsers       []CountStatsResponse `json:"browsers"`
+	Countries      []CountStatsResponse `json:"countries"`
+}
+
+type DailyStatsResponse struct {
+	Day            string `json:"day"`
+	Clicks         int64  `json:"clicks"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+type CountStatsResponse struct {
+	Name   string `json:"name"`
+	Clicks int64  `json:"clicks"`
+}
+
+type GetLinkStatsRequest struct {
+	ID     string `json:"-" validate:"required,uuid"`
+	UserId string `json:"-" validate:"required,max=100"`
+	Days   int    `json:"-" validate:"min=1,max=366"`
+}
+
+type RecordClickRequest struct {
+	Event     *ClickEvent `validate:"required"`
+	Topic     string      `validate:"required"`
+	Partition int32
+	Offset    int64
+}
@@ -0,0 +1,34 @@
+package model
+
+type TotpEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	// Uri is the otpauth URI to show as a QR code
+	Uri string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type EnrollTotpRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type ConfirmTotpRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	Code   string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type DisableTotpRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	// Code is a TOTP or a recovery code
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+type LoginMfaRequest struct {
+	MfaToken string `json:"mfa_token" validate:"required,max=100"`
+	// Code is a TOTP or a recovery code
+	Code      string `json:"code" validate:"required,max=20"`
+	UserAgent string `json:"-"`
+	IpAddress string `json:"-"`
+}
@@ -1,9 +1,20 @@
 package model
 
 type WebResponse[T any] struct {
-	Data   T             `json:"data"`
-	Paging *PageMetadata `json:"paging,omitempty"`
-	Errors string        `json:"errors,omitempty"`
+	Data   T               `json:"data"`
+	Paging *PageMetadata   `json:"paging,omitempty"`
+	Cursor *CursorMetadata `json:"cursor,omitempty"`
+	Errors string          `json:"errors,omitempty"`
+	// Code identifies the error for clients that branch on it
+	Code string `json:"code,omitempty"`
+	// Details lists the individual problems behind a validation error
+	Details []FieldError `json:"details,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 type PageResponse[T any] struct {
@@ -17,3 +28,9 @@ type PageMetadata struct {
 	TotalItem int64 `json:"total_item"`
 	TotalPage int64 `json:"total_page"`
 }
+
+type CursorMetadata struct {
+	Size       int    `json:"size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// QuotaResponse tells how much of their plan a user has used. A max of zero
+// is unlimited and leaves the remaining count out.
+type QuotaResponse struct {
+	Plan                string `json:"plan"`
+	MaxLinks            int    `json:"max_links"`
+	Links               int64  `json:"links"`
+	RemainingLinks      *int64 `json:"remaining_links,omitempty"`
+	MaxLinksPerDay      int    `json:"max_links_per_day"`
+	LinksToday          int64  `json:"links_today"`
+	RemainingLinksToday *int64 `json:"remaining_links_today,omitempty"`
+	// ResetsAt is when the daily count starts over, midnight UTC
+	ResetsAt int64 `json:"resets_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	Browser    string `json:"browser"`
+	OS         string `json:"os"`
+	Device     string `json:"device"`
+	Current    bool   `json:"current"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+}
+
+type ListSessionRequest struct {
+	UserId    string `json:"-" validate:"required,max=100"`
+	SessionId string `json:"-" validate:"max=100"`
+}
+
+type DeleteSessionRequest struct {
+	ID     string `json:"-" validate:"required,max=100"`
+	UserId string `json:"-" validate:"required,max=100"`
+}
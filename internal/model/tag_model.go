@@ -0,0 +1,28 @@
+package model
+
+type TagResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type CreateTagRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+	Name   string `json:"name" validate:"required,max=50,excludesall=0x2C"`
+}
+
+type ListTagRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type UpdateTagRequest struct {
+	ID     string `json:"-" validate:"required,max=100"`
+	UserId string `json:"-" validate:"required,max=100"`
+	Name   string `json:"name" validate:"required,max=50,excludesall=0x2C"`
+}
+
+type DeleteTagRequest struct {
+	ID     string `json:"-" validate:"required,max=100"`
+	UserId string `json:"-" validate:"required,max=100"`
+}
@@ -1,10 +1,20 @@
 package model
 
+const (
+	UserEventCreated = "created"
+	UserEventUpdated = "updated"
+	UserEventLogin   = "login"
+	UserEventLocked  = "locked"
+)
+
 type UserEvent struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	CreatedAt int64  `json:"created_at,omitempty"`
-	UpdatedAt int64  `json:"updated_at,omitempty"`
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type,omitempty"`
+	Name string `json:"name,omitempty"`
+	// LockedUntil is set on locked events
+	LockedUntil int64 `json:"locked_until,omitempty"`
+	CreatedAt   int64 `json:"created_at,omitempty"`
+	UpdatedAt   int64 `json:"updated_at,omitempty"`
 }
 
 func (u *UserEvent) GetId() string {
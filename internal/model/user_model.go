@@ -1,11 +1,23 @@
 package model
 
 type UserResponse struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Token     string `json:"token,omitempty"`
-	CreatedAt int64  `json:"created_at,omitempty"`
-	UpdatedAt int64  `json:"updated_at,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// VerifiedAt is set once Email was confirmed
+	VerifiedAt int64  `json:"verified_at,omitempty"`
+	Token      string `json:"token,omitempty"`
+	// RefreshToken is only returned by login and refresh
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MfaEnabled   bool   `json:"mfa_enabled,omitempty"`
+	// MfaRequired is set by a login that has to be completed with a second
+	// factor at /api/users/_login/_mfa, passing MfaToken along
+	MfaRequired bool   `json:"mfa_required,omitempty"`
+	MfaToken    string `json:"mfa_token,omitempty"`
+	// Quota is only returned for the current user
+	Quota     *QuotaResponse `json:"quota,omitempty"`
+	CreatedAt int64          `json:"created_at,omitempty"`
+	UpdatedAt int64          `json:"updated_at,omitempty"`
 }
 
 type VerifyUserRequest struct {
@@ -16,21 +28,53 @@ type RegisterUserRequest struct {
 	ID       string `json:"id" validate:"required,max=100"`
 	Password string `json:"password" validate:"required,max=100"`
 	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email,max=255"`
 }
 
 type UpdateUserRequest struct {
 	ID       string `json:"-" validate:"required,max=100"`
 	Password string `json:"password,omitempty" validate:"max=100"`
 	Name     string `json:"name,omitempty" validate:"max=100"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email,max=255"`
 }
 
 type LoginUserRequest struct {
-	ID       string `json:"id" validate:"required,max=100"`
-	Password string `json:"password" validate:"required,max=100"`
+	ID        string `json:"id" validate:"required,max=100"`
+	Password  string `json:"password" validate:"required,max=100"`
+	UserAgent string `json:"-"`
+	IpAddress string `json:"-"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=100"`
 }
 
 type LogoutUserRequest struct {
-	ID string `json:"id" validate:"required,max=100"`
+	ID        string `json:"id" validate:"required,max=100"`
+	SessionId string `json:"-" validate:"max=100"`
+	TokenId   string `json:"-" validate:"required,max=100"`
+	ExpiresAt int64  `json:"-"`
+}
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token    string `json:"token" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,max=100"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=100"`
+}
+
+type ResendVerificationRequest struct {
+	UserId string `json:"-" validate:"required,max=100"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required,max=100"`
 }
 
 type GetUserRequest struct {
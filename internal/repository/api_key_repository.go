@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository struct {
+	Repository[entity.ApiKey]
+	Log *logrus.Logger
+}
+
+func NewApiKeyRepository(log *logrus.Logger) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		Log: log,
+	}
+}
+
+func (r *ApiKeyRepository) FindAllByUserId(tx *gorm.DB, userId string) ([]entity.ApiKey, error) {
+	var keys []entity.ApiKey
+	if err := tx.Where("user_id = ?", userId).Order("created_at desc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *ApiKeyRepository) FindByIdAndUserId(tx *gorm.DB, key *entity.ApiKey, id string, userId string) error {
+	return tx.Where("id = ? AND user_id = ?", id, userId).Take(key).Error
+}
+
+func (r *ApiKeyRepository) FindByKeyHash(tx *gorm.DB, key *entity.ApiKey, keyHash string) error {
+	return tx.Where("key_hash = ?", keyHash).Take(key).Error
+}
+
+func (r *ApiKeyRepository) UpdateLastUsedAt(tx *gorm.DB, id string, now int64) error {
+	return tx.Model(new(entity.ApiKey)).Where("id = ?", id).UpdateColumn("last_used_at", now).Error
+}
+
+func (r *ApiKeyRepository) DeleteByUserId(tx *gorm.DB, userId string) error {
+	return tx.Where("user_id = ?", userId).Delete(new(entity.ApiKey)).Error
+}
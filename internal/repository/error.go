@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlNoReferencedRow = 1452
+)
+
+// IsDuplicateKey reports whether err is a MySQL unique violation. When key is
+// not empty only violations of that index are reported; the server names it
+// either as 'key' or 'table.key' at the end of the message.
+func IsDuplicateKey(err error, key string) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return false
+	}
+	if key == "" {
+		return true
+	}
+	return strings.HasSuffix(mysqlErr.Message, "'"+key+"'") || strings.HasSuffix(mysqlErr.Message, "."+key+"'")
+}
+
+// IsForeignKeyViolation reports whether err is a MySQL insert or update that
+// referenced a missing parent row
+func IsForeignKeyViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNoReferencedRow
+}
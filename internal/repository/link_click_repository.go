@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ClickBucket is the number of clicks in a fixed-width time bucket, numbered
+// from the unix epoch
+type ClickBucket struct {
+	Bucket int64
+	Clicks int64
+}
+
+type ClickCount struct {
+	Name   string
+	Clicks int64
+}
+
+type LinkClickRepository struct {
+	Repository[entity.LinkClick]
+	Log *logrus.Logger
+}
+
+func NewLinkClickRepository(log *logrus.Logger) *LinkClickRepository {
+	return &LinkClickRepository{
+		Log: log,
+	}
+}
+
+// CountByBucket groups the clicks of a link in [from, to) into buckets of
+// width milliseconds
+func (r *LinkClickRepository) CountByBucket(tx *gorm.DB, linkId string, from int64, to int64, width int64) ([]ClickBucket, error) {
+	var buckets []ClickBucket
+	err := tx.Model(new(entity.LinkClick)).
+		Select("FLOOR(clicked_at / ?) AS bucket, COUNT(*) AS clicks", width).
+		Where("link_id = ? AND clicked_at >= ? AND clicked_at < ?", linkId, from, to).
+		Group("bucket").
+		Order("bucket ASC").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// CountByColumn groups the clicks of a link in [from, to) by column, which
+// must be one of the dimension columns of link_clicks
+func (r *LinkClickRepository) CountByColumn(tx *gorm.DB, linkId string, column string, from int64, to int64, limit int) ([]ClickCount, error) {
+	var counts []ClickCount
+	err := tx.Model(new(entity.LinkClick)).
+		Select(column+" AS name, COUNT(*) AS clicks").
+		Where("link_id = ? AND clicked_at >= ? AND clicked_at < ?", linkId, from, to).
+		Group(column).
+		Order("clicks DESC").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *LinkClickRepository) CountByLinkId(tx *gorm.DB, linkId string, from int64, to int64) (int64, error) {
+	var total int64
+	err := tx.Model(new(entity.LinkClick)).
+		Where("link_id = ? AND clicked_at >= ? AND clicked_at < ?", linkId, from, to).
+		Count(&total).Error
+	return total, err
+}
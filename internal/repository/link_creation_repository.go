@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LinkCreationRepository struct {
+	Log *logrus.Logger
+}
+
+func NewLinkCreationRepository(log *logrus.Logger) *LinkCreationRepository {
+	return &LinkCreationRepository{
+		Log: log,
+	}
+}
+
+func (r *LinkCreationRepository) Increment(tx *gorm.DB, userId string, day string) error {
+	creation := &entity.LinkCreation{UserId: userId, Day: day, Links: 1}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"links": gorm.Expr("links + ?", 1)}),
+	}).Create(creation).Error
+}
+
+// CountByUserIdAndDay returns how many links userId created on day
+func (r *LinkCreationRepository) CountByUserIdAndDay(tx *gorm.DB, userId string, day string) (int64, error) {
+	var total int64
+	err := tx.Model(new(entity.LinkCreation)).Where("user_id = ? AND day = ?", userId, day).
+		Select("COALESCE(SUM(links), 0)").Scan(&total).Error
+	return total, err
+}
@@ -2,11 +2,17 @@ package repository
 
 import (
 	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// likeEscaper keeps user input from being read as LIKE wildcards
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
 type LinkRepository struct {
 	Repository[entity.Link]
 	Log *logrus.Logger
@@ -22,11 +28,154 @@ func (r *LinkRepository) FindByIdAndUserId(tx *gorm.DB, link *entity.Link, id st
 	return tx.Where("id = ? AND user_id = ?", id, userId).First(link).Error
 }
 
-func (r *LinkRepository) FindAllByUserId(tx *gorm.DB, userId string) ([]entity.Link, error) {
+func (r *LinkRepository) Search(tx *gorm.DB, request *model.ListLinkRequest) ([]entity.Link, int64, error) {
+	var links []entity.Link
+	if err := tx.Scopes(r.FilterLink(request), r.WithTags).
+		Order(request.Sort + " " + request.Order).Order("id " + request.Order).
+		Offset((request.Page - 1) * request.Size).Limit(request.Size).
+		Find(&links).Error; err != nil {
+		r.Log.WithError(err).Error("error searching links")
+		return nil, 0, err
+	}
+
+	var total int64 = 0
+	if err := tx.Model(new(entity.Link)).Scopes(r.FilterLink(request)).Count(&total).Error; err != nil {
+		r.Log.WithError(err).Error("error counting links")
+		return nil, 0, err
+	}
+
+	return links, total, nil
+}
+
+// SearchByCursor pages through the filtered links newest first on the
+// (created_at, id) keyset, so links inserted meanwhile never shift a page. One
+// extra row is fetched to tell whether another page follows.
+func (r *LinkRepository) SearchByCursor(tx *gorm.DB, request *model.ListLinkRequest, cursor *model.LinkCursor) ([]entity.Link, bool, error) {
+	query := tx.Scopes(r.FilterLink(request), r.WithTags)
+
+	order := "DESC"
+	if cursor != nil && cursor.Backward {
+		order = "ASC"
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	} else if cursor != nil {
+		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	var links []entity.Link
+	if err := query.Order("created_at " + order).Order("id " + order).Limit(request.Size + 1).Find(&links).Error; err != nil {
+		r.Log.WithError(err).Error("error searching links by cursor")
+		return nil, false, err
+	}
+
+	hasMore := len(links) > request.Size
+	if hasMore {
+		links = links[:request.Size]
+	}
+
+	// backward pages are read oldest first, flip them back to newest first
+	if order == "ASC" {
+		for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+			links[i], links[j] = links[j], links[i]
+		}
+	}
+
+	return links, hasMore, nil
+}
+
+func (r *LinkRepository) FilterLink(request *model.ListLinkRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("user_id = ?", request.UserId)
+
+		if search := request.Search; search != "" {
+			search = "%" + likeEscaper.Replace(search) + "%"
+			tx = tx.Where("(title LIKE ? OR short_url LIKE ? OR long_url LIKE ?)", search, search, search)
+		}
+
+		if request.IsActive != nil {
+			tx = tx.Where("is_active = ?", *request.IsActive)
+		}
+
+		if request.CreatedFrom > 0 {
+			tx = tx.Where("created_at >= ?", request.CreatedFrom)
+		}
+
+		if request.CreatedTo > 0 {
+			tx = tx.Where("created_at <= ?", request.CreatedTo)
+		}
+
+		// any matches links with one of the tags, all only links with every tag
+		if len(request.Tags) > 0 {
+			tagged := tx.Session(&gorm.Session{NewDB: true}).Table("link_tags").Select("link_tags.link_id").
+				Joins("JOIN tags ON tags.id = link_tags.tag_id").
+				Where("tags.user_id = ? AND tags.name IN ?", request.UserId, request.Tags)
+			if request.TagMode == model.TagModeAll {
+				tagged = tagged.Group("link_tags.link_id").Having("COUNT(*) = ?", len(request.Tags))
+			}
+			tx = tx.Where("id IN (?)", tagged)
+		}
+
+		return tx
+	}
+}
+
+// WithTags loads the tags of the links found, ordered by name
+func (r *LinkRepository) WithTags(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("tags.name")
+	})
+}
+
+func (r *LinkRepository) FindTags(tx *gorm.DB, link *entity.Link) error {
+	return tx.Model(link).Order("tags.name").Association("Tags").Find(&link.Tags)
+}
+
+// ReplaceTags makes tags the only tags of link
+func (r *LinkRepository) ReplaceTags(tx *gorm.DB, link *entity.Link, tags []entity.Tag) error {
+	if len(tags) == 0 {
+		link.Tags = nil
+		return tx.Model(link).Association("Tags").Clear()
+	}
+	return tx.Model(link).Association("Tags").Replace(tags)
+}
+
+// UpdateSettings saves the fields an owner edits. The click count is left
+// out, redirects increment it concurrently and saving the value read before
+// would drop their clicks.
+func (r *LinkRepository) UpdateSettings(tx *gorm.DB, link *entity.Link) error {
+	return tx.Model(link).
+		Select("title", "short_url", "long_url", "redirect_type", "password", "is_active", "expires_at", "max_clicks", "updated_at").
+		Updates(link).Error
+}
+
+func (r *LinkRepository) FindByShortUrl(tx *gorm.DB, link *entity.Link, shortUrl string) error {
+	return tx.Where("short_url = ?", shortUrl).Take(link).Error
+}
+
+// IncrementClickCount counts a visit towards max_clicks and reports false when
+// the link had already used up all of its clicks
+func (r *LinkRepository) IncrementClickCount(tx *gorm.DB, link *entity.Link) (bool, error) {
+	result := tx.Model(new(entity.Link)).
+		Where("id = ? AND (max_clicks IS NULL OR click_count < max_clicks)", link.ID).
+		UpdateColumn("click_count", gorm.Expr("click_count + ?", 1))
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindExpired locks a batch of active links that ran past their expiry time or
+// click limit, skipping rows another worker is already deactivating
+func (r *LinkRepository) FindExpired(tx *gorm.DB, now int64, limit int) ([]entity.Link, error) {
 	var links []entity.Link
-	if err := tx.Where("user_id = ?", userId).Find(&links).Error; err != nil {
-		r.Log.WithError(err).Error("error finding links by user id")
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("is_active = ? AND ((expires_at IS NOT NULL AND expires_at <= ?) OR (max_clicks IS NOT NULL AND click_count >= max_clicks))", true, now).
+		Limit(limit).
+		Find(&links).Error
+	if err != nil {
 		return nil, err
 	}
 	return links, nil
 }
+
+func (r *LinkRepository) CountByUserId(tx *gorm.DB, userId string) (int64, error) {
+	var total int64
+	err := tx.Model(new(entity.Link)).Where("user_id = ?", userId).Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type LinkSequenceRepository struct {
+	Repository[entity.LinkSequence]
+	Log *logrus.Logger
+}
+
+func NewLinkSequenceRepository(log *logrus.Logger) *LinkSequenceRepository {
+	return &LinkSequenceRepository{
+		Log: log,
+	}
+}
+
+// Next allocates the next counter value using the table's auto increment,
+// which stays unique across instances without holding a row lock.
+func (r *LinkSequenceRepository) Next(tx *gorm.DB) (int64, error) {
+	sequence := new(entity.LinkSequence)
+	if err := tx.Create(sequence).Error; err != nil {
+		return 0, err
+	}
+	return sequence.ID, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LinkStatsRepository struct {
+	Log *logrus.Logger
+}
+
+func NewLinkStatsRepository(log *logrus.Logger) *LinkStatsRepository {
+	return &LinkStatsRepository{
+		Log: log,
+	}
+}
+
+// MarkOffset records a consumed message and reports false when it had
+// already been recorded before
+func (r *LinkStatsRepository) MarkOffset(tx *gorm.DB, offset *entity.ClickOffset) (bool, error) {
+	if err := tx.Create(offset).Error; err != nil {
+		if IsDuplicateKey(err, "") {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// AddVisitor reports whether the visitor is new for the link on that day
+func (r *LinkStatsRepository) AddVisitor(tx *gorm.DB, visitor *entity.LinkVisitor) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(visitor)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *LinkStatsRepository) IncrementDaily(tx *gorm.DB, linkId string, day string, uniqueVisitors int64) error {
+	stat := &entity.LinkDailyStat{LinkId: linkId, Day: day, Clicks: 1, UniqueVisitors: uniqueVisitors}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"clicks":          gorm.Expr("clicks + ?", 1),
+			"unique_visitors": gorm.Expr("unique_visitors + ?", uniqueVisitors),
+		}),
+	}).Create(stat).Error
+}
+
+func (r *LinkStatsRepository) IncrementReferrer(tx *gorm.DB, linkId string, referrer string) error {
+	return r.increment(tx, &entity.LinkReferrerStat{LinkId: linkId, Referrer: referrer, Clicks: 1})
+}
+
+func (r *LinkStatsRepository) IncrementBrowser(tx *gorm.DB, linkId string, browser string) error {
+	return r.increment(tx, &entity.LinkBrowserStat{LinkId: linkId, Browser: browser, Clicks: 1})
+}
+
+func (r *LinkStatsRepository) IncrementCountry(tx *gorm.DB, linkId string, country string) error {
+	return r.increment(tx, &entity.LinkCountryStat{LinkId: linkId, Country: country, Clicks: 1})
+}
+
+func (r *LinkStatsRepository) increment(tx *gorm.DB, stat any) error {
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"clicks": gorm.Expr("clicks + ?", 1)}),
+	}).Create(stat).Error
+}
+
+func (r *LinkStatsRepository) FindDailyByLinkId(tx *gorm.DB, linkId string, fromDay string) ([]entity.LinkDailyStat, error) {
+	var stats []entity.LinkDailyStat
+	if err := tx.Where("link_id = ? AND day >= ?", linkId, fromDay).Order("day ASC").Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *LinkStatsRepository) SumClicksByLinkId(tx *gorm.DB, linkId string) (int64, error) {
+	var total int64
+	err := tx.Model(new(entity.LinkDailyStat)).Where("link_id = ?", linkId).
+		Select("COALESCE(SUM(clicks), 0)").Scan(&total).Error
+	return total, err
+}
+
+func (r *LinkStatsRepository) CountVisitorsByLinkId(tx *gorm.DB, linkId string) (int64, error) {
+	var total int64
+	err := tx.Model(new(entity.LinkVisitor)).Where("link_id = ?", linkId).
+		Distinct("ip_hash").Count(&total).Error
+	return total, err
+}
+
+func (r *LinkStatsRepository) FindTopReferrers(tx *gorm.DB, linkId string, limit int) ([]entity.LinkReferrerStat, error) {
+	var stats []entity.LinkReferrerStat
+	if err := tx.Where("link_id = ?", linkId).Order("clicks DESC").Limit(limit).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *LinkStatsRepository) FindTopBrowsers(tx *gorm.DB, linkId string, limit int) ([]entity.LinkBrowserStat, error) {
+	var stats []entity.LinkBrowserStat
+	if err := tx.Where("link_id = ?", linkId).Order("clicks DESC").Limit(limit).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *LinkStatsRepository) FindTopCountries(tx *gorm.DB, linkId string, limit int) ([]entity.LinkCountryStat, error) {
+	var stats []entity.LinkCountryStat
+	if err := tx.Where("link_id = ?", linkId).Order("clicks DESC").Limit(limit).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
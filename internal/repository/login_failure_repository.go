@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type LoginFailureRepository struct {
+	Log *logrus.Logger
+}
+
+func NewLoginFailureRepository(log *logrus.Logger) *LoginFailureRepository {
+	return &LoginFailureRepository{
+		Log: log,
+	}
+}
+
+// Increment counts a failure of subject and returns the new count. A count
+// whose last failure is before resetBefore starts over.
+func (r *LoginFailureRepository) Increment(tx *gorm.DB, subject string, now int64, resetBefore int64) (int, error) {
+	err := tx.Exec(`INSERT INTO login_failures (subject, failures, last_failed_at) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE failures = IF(last_failed_at < ?, 1, failures + 1), last_failed_at = VALUES(last_failed_at)`,
+		subject, now, resetBefore).Error
+	if err != nil {
+		return 0, err
+	}
+
+	failure := new(entity.LoginFailure)
+	if err := tx.Where("subject = ?", subject).Take(failure).Error; err != nil {
+		return 0, err
+	}
+	return failure.Failures, nil
+}
+
+func (r *LoginFailureRepository) FindBySubjects(tx *gorm.DB, subjects []string) ([]entity.LoginFailure, error) {
+	var failures []entity.LoginFailure
+	if err := tx.Where("subject IN ?", subjects).Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+func (r *LoginFailureRepository) DeleteBySubject(tx *gorm.DB, subject string) error {
+	return tx.Where("subject = ?", subject).Delete(new(entity.LoginFailure)).Error
+}
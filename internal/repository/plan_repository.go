@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PlanRepository struct {
+	Repository[entity.Plan]
+	Log *logrus.Logger
+}
+
+func NewPlanRepository(log *logrus.Logger) *PlanRepository {
+	return &PlanRepository{
+		Log: log,
+	}
+}
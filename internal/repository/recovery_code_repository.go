@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	Repository[entity.RecoveryCode]
+	Log *logrus.Logger
+}
+
+func NewRecoveryCodeRepository(log *logrus.Logger) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		Log: log,
+	}
+}
+
+// Use marks the unused code of userId with codeHash as used and reports
+// whether there was one
+func (r *RecoveryCodeRepository) Use(tx *gorm.DB, userId string, codeHash string, now int64) (bool, error) {
+	result := tx.Model(new(entity.RecoveryCode)).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserId(tx *gorm.DB, userId string) error {
+	return tx.Where("user_id = ?", userId).Delete(new(entity.RecoveryCode)).Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository struct {
+	Repository[entity.RefreshToken]
+	Log *logrus.Logger
+}
+
+func NewRefreshTokenRepository(log *logrus.Logger) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		Log: log,
+	}
+}
+
+// FindByTokenHash locks the token row, so two requests racing with the same
+// token can not both rotate it
+func (r *RefreshTokenRepository) FindByTokenHash(tx *gorm.DB, token *entity.RefreshToken, tokenHash string) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).Take(token).Error
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(tx *gorm.DB, familyId string, now int64) error {
+	return tx.Model(new(entity.RefreshToken)).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", now).Error
+}
+
+func (r *RefreshTokenRepository) RevokeByUserId(tx *gorm.DB, userId string, now int64) error {
+	return tx.Model(new(entity.RefreshToken)).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	Repository[entity.Session]
+	Log *logrus.Logger
+}
+
+func NewSessionRepository(log *logrus.Logger) *SessionRepository {
+	return &SessionRepository{
+		Log: log,
+	}
+}
+
+func (r *SessionRepository) FindAllByUserId(tx *gorm.DB, userId string) ([]entity.Session, error) {
+	var sessions []entity.Session
+	if err := tx.Where("user_id = ?", userId).Order("last_seen_at desc").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) FindByIdAndUserId(tx *gorm.DB, session *entity.Session, id string, userId string) error {
+	return tx.Where("id = ? AND user_id = ?", id, userId).Take(session).Error
+}
+
+// Touch moves last_seen_at forward and reports false when the session is gone
+func (r *SessionRepository) Touch(tx *gorm.DB, id string, userId string, now int64) (bool, error) {
+	result := tx.Model(new(entity.Session)).
+		Where("id = ? AND user_id = ?", id, userId).
+		UpdateColumn("last_seen_at", now)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.RowsAffected > 0, result.Error
+	}
+
+	// MySQL does not count rows whose value did not change as affected
+	var total int64
+	err := tx.Model(new(entity.Session)).Where("id = ? AND user_id = ?", id, userId).Count(&total).Error
+	return total > 0, err
+}
+
+func (r *SessionRepository) DeleteByUserId(tx *gorm.DB, userId string) error {
+	return tx.Where("user_id = ?", userId).Delete(new(entity.Session)).Error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	Repository[entity.Tag]
+	Log *logrus.Logger
+}
+
+func NewTagRepository(log *logrus.Logger) *TagRepository {
+	return &TagRepository{
+		Log: log,
+	}
+}
+
+func (r *TagRepository) FindByIdAndUserId(tx *gorm.DB, tag *entity.Tag, id string, userId string) error {
+	return tx.Where("id = ? AND user_id = ?", id, userId).Take(tag).Error
+}
+
+func (r *TagRepository) FindAllByUserId(tx *gorm.DB, userId string) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	if err := tx.Where("user_id = ?", userId).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) FindByUserIdAndNames(tx *gorm.DB, userId string, names []string) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	if err := tx.Where("user_id = ? AND name IN ?", userId, names).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CreateMissing inserts tags, skipping those whose name the user already has
+func (r *TagRepository) CreateMissing(tx *gorm.DB, tags []entity.Tag) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+}
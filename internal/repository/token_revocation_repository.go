@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type TokenRevocationRepository struct {
+	Repository[entity.TokenRevocation]
+	Log *logrus.Logger
+}
+
+func NewTokenRevocationRepository(log *logrus.Logger) *TokenRevocationRepository {
+	return &TokenRevocationRepository{
+		Log: log,
+	}
+}
+
+// FindActiveSince returns unexpired revocations created after since
+func (r *TokenRevocationRepository) FindActiveSince(tx *gorm.DB, since int64, now int64) ([]entity.TokenRevocation, error) {
+	var revocations []entity.TokenRevocation
+	err := tx.Where("created_at >= ? AND expires_at > ?", since, now).Find(&revocations).Error
+	if err != nil {
+		return nil, err
+	}
+	return revocations, nil
+}
+
+func (r *TokenRevocationRepository) DeleteExpired(tx *gorm.DB, now int64) (int64, error) {
+	result := tx.Where("expires_at <= ?", now).Delete(new(entity.TokenRevocation))
+	return result.RowsAffected, result.Error
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -21,3 +22,12 @@ func NewUserRepository(log *logrus.Logger) *UserRepository {
 func (r *UserRepository) FindByToken(db *gorm.DB, user *entity.User, token string) error {
 	return db.Where("token = ?", token).First(user).Error
 }
+
+func (r *UserRepository) FindByEmail(db *gorm.DB, user *entity.User, email string) error {
+	return db.Where("email = ?", email).Take(user).Error
+}
+
+// FindByIdForUpdate locks the user row until tx ends
+func (r *UserRepository) FindByIdForUpdate(tx *gorm.DB, user *entity.User, id string) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(user).Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"devshort-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserTokenRepository struct {
+	Repository[entity.UserToken]
+	Log *logrus.Logger
+}
+
+func NewUserTokenRepository(log *logrus.Logger) *UserTokenRepository {
+	return &UserTokenRepository{
+		Log: log,
+	}
+}
+
+// FindByTokenHash locks the token row, so a token can only be redeemed once
+// even by concurrent requests
+func (r *UserTokenRepository) FindByTokenHash(tx *gorm.DB, token *entity.UserToken, purpose string, tokenHash string) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", tokenHash, purpose).
+		Take(token).Error
+}
+
+// MarkUsedByUserId burns every outstanding token of userId for purpose
+func (r *UserTokenRepository) MarkUsedByUserId(tx *gorm.DB, userId string, purpose string, now int64) error {
+	return tx.Model(new(entity.UserToken)).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userId, purpose).
+		Update("used_at", now).Error
+}
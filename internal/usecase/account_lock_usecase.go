@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/gateway/mail"
+	"devshort-backend/internal/gateway/messaging"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AccountLockUseCase locks accounts that failed too many logins. The owner is
+// mailed a link to unlock early; otherwise the lock runs out by itself.
+type AccountLockUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	UserRepository      *repository.UserRepository
+	UserTokenRepository *repository.UserTokenRepository
+	LoginThrottle       *LoginThrottle
+	MailSender          mail.Sender
+	UserProducer        *messaging.UserProducer
+	Clock               Clock
+	LockDuration        time.Duration
+	// UnlockUrl is the page that reads the token from its query and unlocks the account
+	UnlockUrl      string
+	UnlockTokenTTL time.Duration
+}
+
+func NewAccountLockUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	userRepository *repository.UserRepository, userTokenRepository *repository.UserTokenRepository,
+	loginThrottle *LoginThrottle, mailSender mail.Sender, userProducer *messaging.UserProducer, clock Clock,
+	lockDuration time.Duration, unlockUrl string, unlockTokenTTL time.Duration) *AccountLockUseCase {
+	return &AccountLockUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		UserRepository:      userRepository,
+		UserTokenRepository: userTokenRepository,
+		LoginThrottle:       loginThrottle,
+		MailSender:          mailSender,
+		UserProducer:        userProducer,
+		Clock:               clock,
+		LockDuration:        lockDuration,
+		UnlockUrl:           unlockUrl,
+		UnlockTokenTTL:      unlockTokenTTL,
+	}
+}
+
+// Wait returns an *AppError while logins of userId or from ip back off
+func (c *AccountLockUseCase) Wait(ctx context.Context, userId string, ip string) error {
+	wait, err := c.LoginThrottle.Wait(c.DB.WithContext(ctx), userId, ip)
+	if err != nil {
+		c.Log.Warnf("Failed find login failures : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	if wait <= 0 {
+		return nil
+	}
+	return &AppError{
+		Status:     fiber.StatusTooManyRequests,
+		Code:       "login_throttled",
+		Message:    "Too many failed logins, try again later",
+		RetryAfter: wait,
+	}
+}
+
+// Locked returns an *AppError while user is locked
+func (c *AccountLockUseCase) Locked(user *entity.User) error {
+	if user.LockedUntil == nil {
+		return nil
+	}
+
+	remaining := time.Duration(*user.LockedUntil-c.Clock.Now().UnixMilli()) * time.Millisecond
+	if remaining <= 0 {
+		return nil
+	}
+	return &AppError{
+		Status:     fiber.StatusLocked,
+		Code:       "account_locked",
+		Message:    "Account is locked after too many failed logins",
+		RetryAfter: remaining,
+	}
+}
+
+// Fail records a failed login of userId from ip and locks the account once
+// the failures reach the threshold. user is nil when the id is unknown.
+func (c *AccountLockUseCase) Fail(ctx context.Context, user *entity.User, userId string, ip string) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	failures, err := c.LoginThrottle.Fail(tx, userId, ip)
+	if err != nil {
+		c.Log.Warnf("Failed count login failure : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if user == nil || !c.LoginThrottle.ShouldLock(failures) {
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+		return nil
+	}
+
+	locked := new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, locked, user.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	// failures during a lock neither extend it nor mail the owner again
+	if c.Locked(locked) != nil {
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+		return nil
+	}
+
+	lockedUntil := c.Clock.Now().Add(c.LockDuration).UnixMilli()
+	locked.LockedUntil = &lockedUntil
+	if err := c.UserRepository.Update(tx, locked); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	// an unverified address may belong to anyone, it gets no unlock link
+	var unlockToken string
+	if locked.Email != nil && locked.VerifiedAt != nil {
+		if unlockToken, err = c.issueUnlockToken(tx, locked); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.Log.Warnf("User %s locked after %d failed logins", locked.ID, failures)
+
+	if unlockToken != "" {
+		c.mailUnlockLink(ctx, locked, unlockToken)
+	}
+
+	if c.UserProducer != nil {
+		event := converter.UserToEvent(locked, model.UserEventLocked)
+		c.Log.Info("Publishing user locked event")
+		if err := c.UserProducer.Send(event); err != nil {
+			c.Log.Warnf("Failed publish user locked event : %+v", err)
+		}
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping user locked event")
+	}
+
+	return nil
+}
+
+// Reset forgets the failures of user inside tx after a successful login
+func (c *AccountLockUseCase) Reset(tx *gorm.DB, user *entity.User) error {
+	if err := c.LoginThrottle.Reset(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed reset login failures : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if user.LockedUntil != nil {
+		user.LockedUntil = nil
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+	}
+	return nil
+}
+
+// Unlock lifts a lock with the token from the unlock mail
+func (c *AccountLockUseCase) Unlock(ctx context.Context, request *model.UnlockAccountRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	token := new(entity.UserToken)
+	if err := c.UserTokenRepository.FindByTokenHash(tx, token, entity.UserTokenAccountUnlock, hashOpaqueToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find unlock token : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	now := c.Clock.Now().UnixMilli()
+	if token.UsedAt != nil || token.ExpiresAt <= now {
+		c.Log.Warnf("Unlock token %s is no longer valid", token.ID)
+		return fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, token.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := c.Reset(tx, user); err != nil {
+		return err
+	}
+
+	if err := c.UserTokenRepository.MarkUsedByUserId(tx, user.ID, entity.UserTokenAccountUnlock, now); err != nil {
+		c.Log.Warnf("Failed burn unlock tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s unlocked their account", user.ID)
+	return nil
+}
+
+func (c *AccountLockUseCase) issueUnlockToken(tx *gorm.DB, user *entity.User) (string, error) {
+	now := c.Clock.Now()
+	if err := c.UserTokenRepository.MarkUsedByUserId(tx, user.ID, entity.UserTokenAccountUnlock, now.UnixMilli()); err != nil {
+		c.Log.Warnf("Failed burn previous unlock tokens : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		c.Log.Warnf("Failed to generate unlock token : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+
+	record := &entity.UserToken{
+		ID:        uuid.NewString(),
+		UserId:    user.ID,
+		Purpose:   entity.UserTokenAccountUnlock,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: now.Add(c.UnlockTokenTTL).UnixMilli(),
+	}
+	if err := c.UserTokenRepository.Create(tx, record); err != nil {
+		c.Log.Warnf("Failed create unlock token : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+	return token, nil
+}
+
+func (c *AccountLockUseCase) mailUnlockLink(ctx context.Context, user *entity.User, token string) {
+	message := &mail.Message{
+		To:      *user.Email,
+		Subject: "Your DevShort account was locked",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account was locked for %d minutes after too many failed logins.\n"+
+			"If that was you, unlock it right away with the link below:\n\n%s?token=%s\n\n"+
+			"If it was not you, consider resetting your password.\n",
+			user.Name, int(c.LockDuration.Minutes()), c.UnlockUrl, url.QueryEscape(token)),
+	}
+	if err := c.MailSender.Send(ctx, message); err != nil {
+		c.Log.Warnf("Failed send unlock mail : %+v", err)
+	}
+}
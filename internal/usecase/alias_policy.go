@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AliasPolicy decides which custom short urls users may pick
+type AliasPolicy struct {
+	MinLength     int
+	MaxLength     int
+	Pattern       *regexp.Regexp
+	CaseSensitive bool
+	Reserved      map[string]struct{}
+}
+
+func NewAliasPolicy(minLength int, maxLength int, pattern *regexp.Regexp, caseSensitive bool, reserved []string) *AliasPolicy {
+	policy := &AliasPolicy{
+		MinLength:     minLength,
+		MaxLength:     maxLength,
+		Pattern:       pattern,
+		CaseSensitive: caseSensitive,
+		Reserved:      make(map[string]struct{}, len(reserved)),
+	}
+	for _, word := range reserved {
+		policy.Reserved[strings.ToLower(word)] = struct{}{}
+	}
+	return policy
+}
+
+// Check returns a client facing reason when alias is not acceptable
+func (p *AliasPolicy) Check(alias string) error {
+	if length := len(alias); length < p.MinLength || length > p.MaxLength {
+		return fmt.Errorf("Short url must be between %d and %d characters", p.MinLength, p.MaxLength)
+	}
+	if p.Pattern != nil && !p.Pattern.MatchString(alias) {
+		return fmt.Errorf("Short url may only contain characters matching %s", p.Pattern.String())
+	}
+	// routing ignores case, so reserved words are always matched case-insensitively
+	if _, reserved := p.Reserved[strings.ToLower(alias)]; reserved {
+		return fmt.Errorf("Short url %q is reserved", alias)
+	}
+	return nil
+}
+
+// Normalize returns the form in which alias is stored and looked up
+func (p *AliasPolicy) Normalize(alias string) string {
+	if p.CaseSensitive {
+		return alias
+	}
+	return strings.ToLower(alias)
+}
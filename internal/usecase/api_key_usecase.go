@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ApiKeyPrefix starts every API key, so they are easy to tell from access
+// tokens and to find with secret scanners
+const ApiKeyPrefix = "dsk_"
+
+// apiKeyVisibleLength is how much of the key is kept in the clear
+const apiKeyVisibleLength = len(ApiKeyPrefix) + 8
+
+// apiKeyTouchInterval limits last_used_at writes for busy keys
+const apiKeyTouchInterval = time.Minute
+
+type ApiKeyUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	Validate         *validator.Validate
+	ApiKeyRepository *repository.ApiKeyRepository
+	UserRepository   *repository.UserRepository
+}
+
+func NewApiKeyUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	apiKeyRepository *repository.ApiKeyRepository, userRepository *repository.UserRepository) *ApiKeyUseCase {
+	return &ApiKeyUseCase{
+		DB:               db,
+		Log:              logger,
+		Validate:         validate,
+		ApiKeyRepository: apiKeyRepository,
+		UserRepository:   userRepository,
+	}
+}
+
+// Create mints a key. The response is the only time the full key is shown.
+func (c *ApiKeyUseCase) Create(ctx context.Context, request *model.CreateApiKeyRequest) (*model.ApiKeyResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	if request.ExpiresAt != nil && *request.ExpiresAt <= time.Now().UnixMilli() {
+		return nil, NewAppError(fiber.StatusBadRequest, "invalid_expiry", "Expiry must be in the future")
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	secret, err := newOpaqueToken()
+	if err != nil {
+		c.Log.Warnf("Failed to generate api key : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	key := ApiKeyPrefix + secret
+
+	apiKey := &entity.ApiKey{
+		ID:        uuid.NewString(),
+		UserId:    user.ID,
+		Name:      request.Name,
+		Prefix:    key[:apiKeyVisibleLength],
+		KeyHash:   hashOpaqueToken(key),
+		Scopes:    strings.Join(uniqueScopes(request.Scopes), ","),
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := c.ApiKeyRepository.Create(tx, apiKey); err != nil {
+		c.Log.Warnf("Failed create api key : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.ApiKeyToResponse(apiKey)
+	response.Key = key
+	return response, nil
+}
+
+func (c *ApiKeyUseCase) List(ctx context.Context, request *model.ListApiKeyRequest) ([]model.ApiKeyResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	keys, err := c.ApiKeyRepository.FindAllByUserId(c.DB.WithContext(ctx), request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find api keys : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	responses := make([]model.ApiKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = *converter.ApiKeyToResponse(&key)
+	}
+	return responses, nil
+}
+
+func (c *ApiKeyUseCase) Delete(ctx context.Context, request *model.DeleteApiKeyRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	key := new(entity.ApiKey)
+	if err := c.ApiKeyRepository.FindByIdAndUserId(tx, key, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find api key : %+v", err)
+		return fiber.ErrNotFound
+	}
+
+	if err := c.ApiKeyRepository.Delete(tx, key); err != nil {
+		c.Log.Warnf("Failed delete api key : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// Verify resolves an API key to the user it acts for
+func (c *ApiKeyUseCase) Verify(ctx context.Context, request *model.VerifyApiKeyRequest) (*model.Auth, error) {
+	if err := c.Validate.Struct(request); err != nil || !strings.HasPrefix(request.Key, ApiKeyPrefix) {
+		return nil, fiber.ErrUnauthorized
+	}
+
+	db := c.DB.WithContext(ctx)
+	key := new(entity.ApiKey)
+	if err := c.ApiKeyRepository.FindByKeyHash(db, key, hashOpaqueToken(request.Key)); err != nil {
+		c.Log.Warnf("Failed find api key : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	now := time.Now().UnixMilli()
+	if key.ExpiresAt != nil && *key.ExpiresAt <= now {
+		c.Log.Warnf("Api key %s has expired", key.ID)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	if key.LastUsedAt == nil || now-*key.LastUsedAt >= apiKeyTouchInterval.Milliseconds() {
+		if err := c.ApiKeyRepository.UpdateLastUsedAt(db, key.ID, now); err != nil {
+			c.Log.Warnf("Failed update api key last used : %+v", err)
+		}
+	}
+
+	return &model.Auth{
+		ID:       key.UserId,
+		ApiKeyId: key.ID,
+		Scopes:   strings.Split(key.Scopes, ","),
+	}, nil
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result
+}
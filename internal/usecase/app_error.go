@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"devshort-backend/internal/model"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AppError is an error meant for the client. Status is the HTTP status, Code
+// a stable identifier clients can branch on and Message is for humans.
+// Use cases return it as is and controllers pass it on unchanged.
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	// Details lists the problems of a rejected request field by field
+	Details []model.FieldError
+	// Data is rendered as the response data, such as the quota that ran out
+	Data any
+	// RetryAfter is sent as the Retry-After header when set
+	RetryAfter time.Duration
+}
+
+func NewAppError(status int, code string, message string) *AppError {
+	return &AppError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+// statusCodes names the plain fiber errors, which carry no code of their own
+var statusCodes = map[int]string{
+	fiber.StatusBadRequest:            "bad_request",
+	fiber.StatusUnauthorized:          "unauthorized",
+	fiber.StatusPaymentRequired:       "payment_required",
+	fiber.StatusForbidden:             "forbidden",
+	fiber.StatusNotFound:              "not_found",
+	fiber.StatusMethodNotAllowed:      "method_not_allowed",
+	fiber.StatusConflict:              "conflict",
+	fiber.StatusGone:                  "gone",
+	fiber.StatusRequestEntityTooLarge: "payload_too_large",
+	fiber.StatusUnsupportedMediaType:  "unsupported_media_type",
+	fiber.StatusUnprocessableEntity:   "unprocessable_entity",
+	fiber.StatusLocked:                "locked",
+	fiber.StatusTooManyRequests:       "too_many_requests",
+	fiber.StatusInternalServerError:   "internal_error",
+	fiber.StatusServiceUnavailable:    "service_unavailable",
+}
+
+// ToAppError turns err into the *AppError sent to the client. A *fiber.Error
+// keeps its status and message; anything else is an internal error whose
+// message is not shown.
+func ToAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code, ok := statusCodes[fiberErr.Code]
+		if !ok {
+			code = "error"
+		}
+		return NewAppError(fiberErr.Code, code, fiberErr.Message)
+	}
+
+	return NewAppError(fiber.StatusInternalServerError, "internal_error", "Internal server error")
+}
+
+// NewValidationError explains a failed Validate.Struct field by field. The
+// detail codes are the names of the failed validation tags.
+func NewValidationError(err error) *AppError {
+	appErr := NewAppError(fiber.StatusBadRequest, "invalid_request", "Request is invalid")
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			appErr.Details = append(appErr.Details, model.FieldError{
+				Field:   fieldError.Field(),
+				Code:    fieldError.Tag(),
+				Message: validationMessage(fieldError),
+			})
+		}
+	}
+	return appErr
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	field, param := fieldError.Field(), fieldError.Param()
+	unit := ""
+	switch fieldError.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fieldError.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "url", "http_url":
+		return field + " must be a valid URL"
+	case "uuid":
+		return field + " must be a UUID"
+	case "numeric":
+		return field + " must be a number"
+	case "timezone":
+		return field + " must be an IANA time zone"
+	case "excludesall":
+		return field + " contains a character that is not allowed"
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s%s", field, param, unit)
+	case "oneof":
+		return field + " must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "gt":
+		return field + " must be greater than " + param
+	case "gte":
+		return field + " must be at least " + param
+	case "lt":
+		return field + " must be less than " + param
+	case "lte":
+		return field + " must be at most " + param
+	default:
+		return field + " is invalid"
+	}
+}
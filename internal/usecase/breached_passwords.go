@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// BreachedPasswords looks passwords up in a local copy of a breached password
+// list: one upper case SHA-1 hash per line, optionally followed by :count,
+// ordered by hash, as in the Pwned Passwords download. Only the hash ever
+// leaves the password, and the file is binary searched on disk so lists with
+// hundreds of millions of lines need no memory.
+type BreachedPasswords struct {
+	Path string
+}
+
+func NewBreachedPasswords(path string) (*BreachedPasswords, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	_ = file.Close()
+	return &BreachedPasswords{Path: path}, nil
+}
+
+func (b *BreachedPasswords) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	file, err := os.Open(b.Path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	low, high := int64(0), info.Size()
+	for low < high {
+		middle := low + (high-low)/2
+		line, start, end, err := lineFrom(file, middle, info.Size())
+		if err != nil {
+			return false, err
+		}
+		if start >= high {
+			high = middle
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		switch strings.Compare(strings.ToUpper(hash), target) {
+		case 0:
+			return true, nil
+		case -1:
+			low = end
+		default:
+			high = middle
+		}
+	}
+	return false, nil
+}
+
+// lineFrom returns the first line starting at or after offset, with the
+// offsets of its start and of the next line
+func lineFrom(file *os.File, offset int64, size int64) (string, int64, int64, error) {
+	start := offset
+	if offset > 0 {
+		// the line is whole only if the byte before it is a line break
+		start = offset - 1
+	}
+	reader := bufio.NewReaderSize(io.NewSectionReader(file, start, size-start), 128)
+
+	if offset > 0 {
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return "", size, size, nil
+		}
+		if err != nil {
+			return "", 0, 0, err
+		}
+		start += int64(len(skipped))
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", 0, 0, err
+	}
+	return strings.TrimRight(line, "\r\n"), start, start + int64(len(line)), nil
+}
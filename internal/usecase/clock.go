@@ -0,0 +1,14 @@
+package usecase
+
+import "time"
+
+// Clock tells the time, so tests can move it forward instead of sleeping
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
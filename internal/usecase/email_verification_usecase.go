@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/gateway/mail"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrEmailNotVerified is returned for actions reserved to verified accounts
+var ErrEmailNotVerified = NewAppError(fiber.StatusForbidden, "email_not_verified", "Email address is not verified")
+
+type EmailVerificationUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	UserRepository      *repository.UserRepository
+	UserTokenRepository *repository.UserTokenRepository
+	MailSender          mail.Sender
+	// VerifyUrl is the page that reads the token from its query and confirms the email
+	VerifyUrl string
+	TokenTTL  time.Duration
+}
+
+func NewEmailVerificationUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	userRepository *repository.UserRepository, userTokenRepository *repository.UserTokenRepository,
+	mailSender mail.Sender, verifyUrl string, tokenTTL time.Duration) *EmailVerificationUseCase {
+	return &EmailVerificationUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		UserRepository:      userRepository,
+		UserTokenRepository: userTokenRepository,
+		MailSender:          mailSender,
+		VerifyUrl:           verifyUrl,
+		TokenTTL:            tokenTTL,
+	}
+}
+
+// Claim frees email for userId inside tx when another account holds it
+// without having verified it. Nobody proved to own such an address, so it must
+// not keep its owner out; the other account loses it along with its pending
+// verification link. Verified addresses are left to the unique index.
+func (c *EmailVerificationUseCase) Claim(tx *gorm.DB, userId string, email string) error {
+	holder := new(entity.User)
+	if err := c.UserRepository.FindByEmail(tx, holder, email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		c.Log.Warnf("Failed find user by email : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	if holder.ID == userId || holder.VerifiedAt != nil {
+		return nil
+	}
+
+	holder.Email = nil
+	if err := c.UserRepository.Update(tx, holder); err != nil {
+		c.Log.Warnf("Failed release unverified email : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	if err := c.UserTokenRepository.MarkUsedByUserId(tx, holder.ID, entity.UserTokenEmailVerification, time.Now().UnixMilli()); err != nil {
+		c.Log.Warnf("Failed burn verification tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s lost their unverified email to user %s", holder.ID, userId)
+	return nil
+}
+
+// Issue stores a new verification token for the email of user inside tx and
+// burns the earlier ones, so only a link for the current address works
+func (c *EmailVerificationUseCase) Issue(tx *gorm.DB, user *entity.User) (string, error) {
+	now := time.Now()
+	if err := c.UserTokenRepository.MarkUsedByUserId(tx, user.ID, entity.UserTokenEmailVerification, now.UnixMilli()); err != nil {
+		c.Log.Warnf("Failed burn previous verification tokens : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		c.Log.Warnf("Failed to generate verification token : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+
+	record := &entity.UserToken{
+		ID:        uuid.NewString(),
+		UserId:    user.ID,
+		Purpose:   entity.UserTokenEmailVerification,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: now.Add(c.TokenTTL).UnixMilli(),
+	}
+	if err := c.UserTokenRepository.Create(tx, record); err != nil {
+		c.Log.Warnf("Failed create verification token : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+	return token, nil
+}
+
+// Mail sends the verification link for token, call it after the transaction
+// that issued the token committed. Failures are only logged; the user can ask
+// for another mail.
+func (c *EmailVerificationUseCase) Mail(ctx context.Context, user *entity.User, token string) {
+	message := &mail.Message{
+		To:      *user.Email,
+		Subject: "Verify your DevShort email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm that this is your email address by opening the link below:\n\n%s?token=%s\n\n"+
+			"The link expires in %d hours.\n",
+			user.Name, c.VerifyUrl, url.QueryEscape(token), int(c.TokenTTL.Hours())),
+	}
+	if err := c.MailSender.Send(ctx, message); err != nil {
+		c.Log.Warnf("Failed send verification mail : %+v", err)
+	}
+}
+
+// Resend mails a new verification link to the current email of the user
+func (c *EmailVerificationUseCase) Resend(ctx context.Context, request *model.ResendVerificationRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return fiber.ErrNotFound
+	}
+
+	if user.Email == nil {
+		return NewAppError(fiber.StatusBadRequest, "email_missing", "Account has no email address")
+	}
+	if user.VerifiedAt != nil {
+		return NewAppError(fiber.StatusConflict, "email_already_verified", "Email address is already verified")
+	}
+
+	token, err := c.Issue(tx, user)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.Mail(ctx, user, token)
+	return nil
+}
+
+func (c *EmailVerificationUseCase) Verify(ctx context.Context, request *model.VerifyEmailRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	token := new(entity.UserToken)
+	if err := c.UserTokenRepository.FindByTokenHash(tx, token, entity.UserTokenEmailVerification, hashOpaqueToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find verification token : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+
+	now := time.Now().UnixMilli()
+	if token.UsedAt != nil || token.ExpiresAt <= now {
+		c.Log.Warnf("Verification token %s is no longer valid", token.ID)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, token.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrBadRequest
+	}
+	if user.Email == nil {
+		c.Log.Warnf("User %s has no email to verify", user.ID)
+		return nil, fiber.ErrBadRequest
+	}
+
+	user.VerifiedAt = &now
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	token.UsedAt = &now
+	if err := c.UserTokenRepository.Update(tx, token); err != nil {
+		c.Log.Warnf("Failed save verification token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s verified their email", user.ID)
+	return converter.UserToResponse(user), nil
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// every timezone offset in use is a multiple of 15 minutes, so buckets of
+	// this width can be regrouped into local hours, days and weeks
+	analyticsBucketWidth  = 15 * time.Minute
+	analyticsDefaultRange = 30
+	analyticsMaxPoints    = 1000
+)
+
+// breakdownColumns whitelists the link_clicks columns a breakdown may group by
+var breakdownColumns = map[string]string{
+	"referrer": "referrer",
+	"device":   "device",
+	"os":       "os",
+	"browser":  "browser",
+	"country":  "country",
+}
+
+type LinkAnalyticsUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	LinkRepository      *repository.LinkRepository
+	LinkClickRepository *repository.LinkClickRepository
+}
+
+func NewLinkAnalyticsUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	linkRepository *repository.LinkRepository, linkClickRepository *repository.LinkClickRepository) *LinkAnalyticsUseCase {
+	return &LinkAnalyticsUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		LinkRepository:      linkRepository,
+		LinkClickRepository: linkClickRepository,
+	}
+}
+
+func (c *LinkAnalyticsUseCase) TimeSeries(ctx context.Context, request *model.GetTimeSeriesRequest) (*model.TimeSeriesResponse, error) {
+	tx := c.DB.WithContext(ctx)
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request")
+		return nil, NewValidationError(err)
+	}
+
+	location, from, to, err := parseAnalyticsRange(request.Timezone, request.From, request.To)
+	if err != nil {
+		c.Log.WithError(err).Error("invalid analytics range")
+		return nil, err
+	}
+
+	// align the range to whole local buckets so the first and last point are complete
+	start := truncateToInterval(from, request.Interval)
+	var starts []time.Time
+	for bucket := start; bucket.Before(to); bucket = nextInterval(bucket, request.Interval) {
+		if len(starts) == analyticsMaxPoints {
+			return nil, NewAppError(fiber.StatusBadRequest, "too_many_points", "Range contains too many points for this interval")
+		}
+		starts = append(starts, bucket)
+	}
+
+	link := new(entity.Link)
+	if err := c.LinkRepository.FindByIdAndUserId(tx, link, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find link")
+		return nil, fiber.ErrNotFound
+	}
+
+	end := to
+	if len(starts) > 0 {
+		end = nextInterval(starts[len(starts)-1], request.Interval)
+	}
+
+	width := analyticsBucketWidth.Milliseconds()
+	buckets, err := c.LinkClickRepository.CountByBucket(tx, link.ID, start.UnixMilli(), end.UnixMilli(), width)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count clicks by bucket")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	clicks := make(map[int64]int64, len(starts))
+	var total int64
+	for _, bucket := range buckets {
+		local := time.UnixMilli(bucket.Bucket * width).In(location)
+		clicks[truncateToInterval(local, request.Interval).UnixMilli()] += bucket.Clicks
+		total += bucket.Clicks
+	}
+
+	response := &model.TimeSeriesResponse{
+		Interval: request.Interval,
+		Timezone: location.String(),
+		From:     start.Format(time.RFC3339),
+		To:       end.Format(time.RFC3339),
+		Total:    total,
+		Points:   make([]model.TimeSeriesPointResponse, len(starts)),
+	}
+	for i, bucketStart := range starts {
+		response.Points[i] = model.TimeSeriesPointResponse{
+			Start:  bucketStart.Format(time.RFC3339),
+			Clicks: clicks[bucketStart.UnixMilli()],
+		}
+	}
+
+	return response, nil
+}
+
+func (c *LinkAnalyticsUseCase) Breakdown(ctx context.Context, request *model.GetBreakdownRequest) (*model.BreakdownResponse, error) {
+	tx := c.DB.WithContext(ctx)
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request")
+		return nil, NewValidationError(err)
+	}
+
+	_, from, to, err := parseAnalyticsRange(request.Timezone, request.From, request.To)
+	if err != nil {
+		c.Log.WithError(err).Error("invalid analytics range")
+		return nil, err
+	}
+
+	link := new(entity.Link)
+	if err := c.LinkRepository.FindByIdAndUserId(tx, link, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find link")
+		return nil, fiber.ErrNotFound
+	}
+
+	counts, err := c.LinkClickRepository.CountByColumn(tx, link.ID, breakdownColumns[request.Dimension], from.UnixMilli(), to.UnixMilli(), request.Limit)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count clicks by dimension")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	total, err := c.LinkClickRepository.CountByLinkId(tx, link.ID, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count clicks")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := &model.BreakdownResponse{
+		Dimension: request.Dimension,
+		From:      from.Format(time.RFC3339),
+		To:        to.Format(time.RFC3339),
+		Total:     total,
+		Items:     make([]model.CountStatsResponse, len(counts)),
+	}
+	for i, count := range counts {
+		response.Items[i] = model.CountStatsResponse{Name: count.Name, Clicks: count.Clicks}
+	}
+
+	return response, nil
+}
+
+// parseAnalyticsRange resolves from and to in the requested timezone. Both
+// accept a date, which covers the whole local day, or an RFC 3339 timestamp.
+// Without from the range covers the last 30 local days up to now.
+func parseAnalyticsRange(timezone string, from string, to string) (*time.Location, time.Time, time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, NewAppError(fiber.StatusBadRequest, "invalid_timezone", "Unknown timezone")
+	}
+
+	end := time.Now().In(location)
+	if to != "" {
+		if end, err = parseAnalyticsTime(to, location, true); err != nil {
+			return nil, time.Time{}, time.Time{}, err
+		}
+	}
+
+	start := truncateToInterval(end, "day").AddDate(0, 0, -(analyticsDefaultRange - 1))
+	if from != "" {
+		if start, err = parseAnalyticsTime(from, location, false); err != nil {
+			return nil, time.Time{}, time.Time{}, err
+		}
+	}
+
+	if !start.Before(end) {
+		return nil, time.Time{}, time.Time{}, NewAppError(fiber.StatusBadRequest, "invalid_range", "From must be before to")
+	}
+	return location, start, end, nil
+}
+
+func parseAnalyticsTime(value string, location *time.Location, endOfDay bool) (time.Time, error) {
+	if day, err := time.ParseInLocation(time.DateOnly, value, location); err == nil {
+		if endOfDay {
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return timestamp.In(location), nil
+	}
+	return time.Time{}, NewAppError(fiber.StatusBadRequest, "invalid_date", "Dates must be YYYY-MM-DD or RFC 3339 timestamps")
+}
+
+// truncateToInterval returns the local start of the hour, day or ISO week
+// containing t, in t's location
+func truncateToInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return t.Add(time.Hour)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/gateway/messaging"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const linkExpiryBatchSize = 100
+
+type LinkExpiryUseCase struct {
+	DB             *gorm.DB
+	Log            *logrus.Logger
+	LinkRepository *repository.LinkRepository
+	LinkProducer   *messaging.LinkProducer
+}
+
+func NewLinkExpiryUseCase(db *gorm.DB, logger *logrus.Logger,
+	linkRepository *repository.LinkRepository, linkProducer *messaging.LinkProducer) *LinkExpiryUseCase {
+	return &LinkExpiryUseCase{
+		DB:             db,
+		Log:            logger,
+		LinkRepository: linkRepository,
+		LinkProducer:   linkProducer,
+	}
+}
+
+// DeactivateExpired deactivates every active link past its expiry time or
+// click limit, one batch per transaction, and returns how many it changed
+func (c *LinkExpiryUseCase) DeactivateExpired(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		links, err := c.deactivateBatch(ctx)
+		if err != nil {
+			return total, err
+		}
+		total += len(links)
+
+		for _, link := range links {
+			c.publish(&link)
+		}
+
+		if len(links) < linkExpiryBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (c *LinkExpiryUseCase) deactivateBatch(ctx context.Context) ([]entity.Link, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	now := time.Now().UnixMilli()
+	links, err := c.LinkRepository.FindExpired(tx, now, linkExpiryBatchSize)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find expired links")
+		return nil, err
+	}
+
+	for i := range links {
+		links[i].IsActive = false
+		links[i].UpdatedAt = now
+		if err := c.LinkRepository.Update(tx, &links[i]); err != nil {
+			c.Log.WithError(err).Error("failed to deactivate link")
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (c *LinkExpiryUseCase) publish(link *entity.Link) {
+	if c.LinkProducer == nil {
+		c.Log.Info("Kafka producer is disabled, skipping link expired event")
+		return
+	}
+
+	if err := c.LinkProducer.Send(converter.LinkToEvent(link)); err != nil {
+		c.Log.WithError(err).Error("failed to publish link expired event")
+		return
+	}
+	c.Log.Infof("Published link expired event for link %s", link.ID)
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// LinkQuota applies the plan of a user to link creation. The days of the
+// daily quota run from midnight to midnight UTC and count every link created
+// on them, deleted or not.
+type LinkQuota struct {
+	Log                    *logrus.Logger
+	LinkRepository         *repository.LinkRepository
+	LinkCreationRepository *repository.LinkCreationRepository
+	PlanRepository         *repository.PlanRepository
+	Clock                  Clock
+}
+
+func NewLinkQuota(logger *logrus.Logger, linkRepository *repository.LinkRepository,
+	linkCreationRepository *repository.LinkCreationRepository, planRepository *repository.PlanRepository, clock Clock) *LinkQuota {
+	return &LinkQuota{
+		Log:                    logger,
+		LinkRepository:         linkRepository,
+		LinkCreationRepository: linkCreationRepository,
+		PlanRepository:         planRepository,
+		Clock:                  clock,
+	}
+}
+
+// Usage counts the links of user against their plan
+func (q *LinkQuota) Usage(db *gorm.DB, user *entity.User) (*model.QuotaResponse, error) {
+	plan := new(entity.Plan)
+	if err := q.PlanRepository.FindById(db, plan, user.PlanId); err != nil {
+		q.Log.Warnf("Failed find plan by id : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	now := q.Clock.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	links, err := q.LinkRepository.CountByUserId(db, user.ID)
+	if err != nil {
+		q.Log.Warnf("Failed count links : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	linksToday, err := q.LinkCreationRepository.CountByUserIdAndDay(db, user.ID, dayStart.Format(time.DateOnly))
+	if err != nil {
+		q.Log.Warnf("Failed count links created today : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.QuotaResponse{
+		Plan:                plan.ID,
+		MaxLinks:            plan.MaxLinks,
+		Links:               links,
+		RemainingLinks:      remainingQuota(plan.MaxLinks, links),
+		MaxLinksPerDay:      plan.MaxLinksPerDay,
+		LinksToday:          linksToday,
+		RemainingLinksToday: remainingQuota(plan.MaxLinksPerDay, linksToday),
+		ResetsAt:            dayStart.AddDate(0, 0, 1).UnixMilli(),
+	}, nil
+}
+
+// Check returns an *AppError carrying the usage when user may not create
+// another link.
+// tx has to hold the user row locked, so concurrent creates are counted one
+// after the other.
+func (q *LinkQuota) Check(tx *gorm.DB, user *entity.User) error {
+	quota, err := q.Usage(tx, user)
+	if err != nil {
+		return err
+	}
+
+	if quota.RemainingLinks != nil && *quota.RemainingLinks == 0 {
+		return &AppError{
+			Status:  fiber.StatusPaymentRequired,
+			Code:    "link_quota_exceeded",
+			Message: "Your plan does not allow more links",
+			Data:    quota,
+		}
+	}
+
+	if quota.RemainingLinksToday != nil && *quota.RemainingLinksToday == 0 {
+		return &AppError{
+			Status:     fiber.StatusTooManyRequests,
+			Code:       "daily_link_quota_exceeded",
+			Message:    "Your plan does not allow more links today",
+			Data:       quota,
+			RetryAfter: time.Duration(quota.ResetsAt-q.Clock.Now().UnixMilli()) * time.Millisecond,
+		}
+	}
+
+	return nil
+}
+
+// Record counts a link user created inside tx, the same tx that checked the
+// quota
+func (q *LinkQuota) Record(tx *gorm.DB, user *entity.User) error {
+	day := q.Clock.Now().UTC().Format(time.DateOnly)
+	if err := q.LinkCreationRepository.Increment(tx, user.ID, day); err != nil {
+		q.Log.Warnf("Failed count created link : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+func remainingQuota(limit int, used int64) *int64 {
+	if limit <= 0 {
+		return nil
+	}
+	remaining := max(int64(limit)-used, 0)
+	return &remaining
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	directReferrer = "Direct"
+	topStatsLimit  = 10
+)
+
+type LinkStatsUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	LinkRepository      *repository.LinkRepository
+	LinkStatsRepository *repository.LinkStatsRepository
+	LinkClickRepository *repository.LinkClickRepository
+}
+
+func NewLinkStatsUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	linkRepository *repository.LinkRepository, linkStatsRepository *repository.LinkStatsRepository,
+	linkClickRepository *repository.LinkClickRepository) *LinkStatsUseCase {
+	return &LinkStatsUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		LinkRepository:      linkRepository,
+		LinkStatsRepository: linkStatsRepository,
+		LinkClickRepository: linkClickRepository,
+	}
+}
+
+// Record folds one click into the per-link aggregates. The message offset is
+// stored in the same transaction, so a redelivered message is a no-op, and
+// the click id is the primary key, so is a click published twice.
+func (c *LinkStatsUseCase) Record(ctx context.Context, request *model.RecordClickRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("invalid click record request")
+		return err
+	}
+
+	event := request.Event
+	fresh, err := c.LinkStatsRepository.MarkOffset(tx, &entity.ClickOffset{
+		Topic:     request.Topic,
+		Partition: request.Partition,
+		Offset:    request.Offset,
+	})
+	if err != nil {
+		c.Log.WithError(err).Error("failed to mark click offset")
+		return err
+	}
+	if !fresh {
+		c.Log.Infof("click at %s/%d/%d was already processed", request.Topic, request.Partition, request.Offset)
+		return nil
+	}
+
+	day := time.UnixMilli(event.Timestamp).UTC().Format(time.DateOnly)
+	userAgent := ParseUserAgent(event.UserAgent)
+	referrer := normalizeReferrer(event.Referrer)
+	country := normalizeCountry(event.Country)
+
+	click := &entity.LinkClick{
+		ID:        event.ID,
+		LinkId:    event.LinkId,
+		ClickedAt: event.Timestamp,
+		Referrer:  referrer,
+		Device:    userAgent.Device,
+		OS:        userAgent.OS,
+		Browser:   userAgent.Browser,
+		Country:   country,
+		IpHash:    event.IpHash,
+	}
+	if click.ID == "" {
+		click.ID = uuid.NewString()
+	}
+	if err := c.LinkClickRepository.Create(tx, click); err != nil {
+		// a producer retry publishes the same click again under a new offset
+		if repository.IsDuplicateKey(err, "PRIMARY") {
+			c.Log.Infof("click %s was already recorded", click.ID)
+			if err := tx.Commit().Error; err != nil {
+				c.Log.WithError(err).Error("failed to commit transaction")
+				return err
+			}
+			return nil
+		}
+		return c.recordError(err, event)
+	}
+
+	var uniqueVisitors int64
+	if event.IpHash != "" {
+		isNew, err := c.LinkStatsRepository.AddVisitor(tx, &entity.LinkVisitor{LinkId: event.LinkId, Day: day, IpHash: event.IpHash})
+		if err != nil {
+			return c.recordError(err, event)
+		}
+		if isNew {
+			uniqueVisitors = 1
+		}
+	}
+
+	if err := c.LinkStatsRepository.IncrementDaily(tx, event.LinkId, day, uniqueVisitors); err != nil {
+		return c.recordError(err, event)
+	}
+	if err := c.LinkStatsRepository.IncrementReferrer(tx, event.LinkId, referrer); err != nil {
+		return c.recordError(err, event)
+	}
+	if err := c.LinkStatsRepository.IncrementBrowser(tx, event.LinkId, userAgent.Browser); err != nil {
+		return c.recordError(err, event)
+	}
+	if err := c.LinkStatsRepository.IncrementCountry(tx, event.LinkId, country); err != nil {
+		return c.recordError(err, event)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.WithError(err).Error("failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// recordError drops clicks of links deleted after the click was published,
+// anything else is returned so the message is retried
+func (c *LinkStatsUseCase) recordError(err error, event *model.ClickEvent) error {
+	if repository.IsForeignKeyViolation(err) {
+		c.Log.Warnf("dropping click %s of deleted link %s", event.ID, event.LinkId)
+		return nil
+	}
+	c.Log.WithError(err).Error("failed to record click")
+	return err
+}
+
+func (c *LinkStatsUseCase) Get(ctx context.Context, request *model.GetLinkStatsRequest) (*model.LinkStatsResponse, error) {
+	tx := c.DB.WithContext(ctx)
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request")
+		return nil, NewValidationError(err)
+	}
+
+	link := new(entity.Link)
+	if err := c.LinkRepository.FindByIdAndUserId(tx, link, request.ID, request.UserId); err != nil {
+		c.Log.WithError(err).Error("failed to find link")
+		return nil, fiber.ErrNotFound
+	}
+
+	fromDay := time.Now().UTC().AddDate(0, 0, -(request.Days - 1)).Format(time.DateOnly)
+	daily, err := c.LinkStatsRepository.FindDailyByLinkId(tx, link.ID, fromDay)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find daily stats")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	totalClicks, err := c.LinkStatsRepository.SumClicksByLinkId(tx, link.ID)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to sum clicks")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	uniqueVisitors, err := c.LinkStatsRepository.CountVisitorsByLinkId(tx, link.ID)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to count visitors")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	referrers, err := c.LinkStatsRepository.FindTopReferrers(tx, link.ID, topStatsLimit)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find referrer stats")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	browsers, err := c.LinkStatsRepository.FindTopBrowsers(tx, link.ID, topStatsLimit)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find browser stats")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	countries, err := c.LinkStatsRepository.FindTopCountries(tx, link.ID, topStatsLimit)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to find country stats")
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := &model.LinkStatsResponse{
+		LinkId:         link.ID,
+		TotalClicks:    totalClicks,
+		UniqueVisitors: uniqueVisitors,
+		Daily:          make([]model.DailyStatsResponse, len(daily)),
+		TopReferrers:   make([]model.CountStatsResponse, len(referrers)),
+		Browsers:       make([]model.CountStatsResponse, len(browsers)),
+		Countries:      make([]model.CountStatsResponse, len(countries)),
+	}
+	for i, stat := range daily {
+		response.Daily[i] = *converter.DailyStatToResponse(&stat)
+	}
+	for i, stat := range referrers {
+		response.TopReferrers[i] = *converter.ReferrerStatToResponse(&stat)
+	}
+	for i, stat := range browsers {
+		response.Browsers[i] = *converter.BrowserStatToResponse(&stat)
+	}
+	for i, stat := range countries {
+		response.Countries[i] = *converter.CountryStatToResponse(&stat)
+	}
+
+	return response, nil
+}
+
+// normalizeReferrer groups referrers by host, so every page of a site counts
+// towards the same entry
+func normalizeReferrer(referrer string) string {
+	if referrer == "" {
+		return directReferrer
+	}
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Host == "" {
+		return directReferrer
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	return host
+}
+
+func normalizeCountry(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	// XX and T1 are what CDNs send for unknown and Tor traffic
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		return unknownDimension
+	}
+	return country
+}
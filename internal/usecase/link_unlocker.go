@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"devshort-backend/internal/entity"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LinkUnlocker issues the signed tokens that let a visitor through a password
+// protected link, and throttles password guesses per link. Tokens are bound to
+// the password hash, so changing the password locks everyone out again.
+// Guesses are counted per link and client, so one client running out of
+// guesses does not lock the link for everyone else.
+type LinkUnlocker struct {
+	Secret      []byte
+	TokenTTL    time.Duration
+	MaxAttempts int
+	Window      time.Duration
+
+	mutex    sync.Mutex
+	failures map[string]*unlockFailures
+}
+
+type unlockFailures struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewLinkUnlocker(secret []byte, tokenTTL time.Duration, maxAttempts int, window time.Duration) *LinkUnlocker {
+	return &LinkUnlocker{
+		Secret:      secret,
+		TokenTTL:    tokenTTL,
+		MaxAttempts: maxAttempts,
+		Window:      window,
+		failures:    map[string]*unlockFailures{},
+	}
+}
+
+// Sign returns a token for link and the unix milli time it stops being valid
+func (u *LinkUnlocker) Sign(link *entity.Link, now time.Time) (string, int64) {
+	expiresAt := now.Add(u.TokenTTL).UnixMilli()
+	payload := strconv.FormatInt(expiresAt, 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(u.sign(link, payload)), expiresAt
+}
+
+func (u *LinkUnlocker) Verify(link *entity.Link, token string, now time.Time) bool {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, u.sign(link, payload)) {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(payload, 10, 64)
+	return err == nil && now.UnixMilli() < expiresAt
+}
+
+// Blocked reports how long guesses of client for linkId are refused, zero
+// when allowed. client identifies the visitor, such as a hash of their IP.
+func (u *LinkUnlocker) Blocked(linkId string, client string, now time.Time) time.Duration {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	key := unlockSubject(linkId, client)
+	failures, ok := u.failures[key]
+	if !ok {
+		return 0
+	}
+	if !now.Before(failures.resetAt) {
+		delete(u.failures, key)
+		return 0
+	}
+	if failures.count < u.MaxAttempts {
+		return 0
+	}
+	return failures.resetAt.Sub(now)
+}
+
+// Fail records a wrong password of client. The window starts at the first
+// failure, so a client gets at most MaxAttempts guesses per Window and link.
+func (u *LinkUnlocker) Fail(linkId string, client string, now time.Time) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.sweep(now)
+
+	key := unlockSubject(linkId, client)
+	failures, ok := u.failures[key]
+	if !ok || !now.Before(failures.resetAt) {
+		failures = &unlockFailures{resetAt: now.Add(u.Window)}
+		u.failures[key] = failures
+	}
+	failures.count++
+}
+
+func (u *LinkUnlocker) Reset(linkId string, client string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	delete(u.failures, unlockSubject(linkId, client))
+}
+
+// sweep drops finished windows so clients that never retry do not pile up
+func (u *LinkUnlocker) sweep(now time.Time) {
+	for key, failures := range u.failures {
+		if !now.Before(failures.resetAt) {
+			delete(u.failures, key)
+		}
+	}
+}
+
+func unlockSubject(linkId string, client string) string {
+	return linkId + "/" + client
+}
+
+func (u *LinkUnlocker) sign(link *entity.Link, payload string) []byte {
+	mac := hmac.New(sha256.New, u.Secret)
+	mac.Write([]byte(link.ID))
+	mac.Write([]byte{0})
+	if link.Password != nil {
+		mac.Write([]byte(*link.Password))
+	}
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
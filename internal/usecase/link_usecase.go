@@ -13,27 +13,48 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type LinkUseCase struct {
-	DB             *gorm.DB
-	Log            *logrus.Logger
-	Validate       *validator.Validate
-	LinkRepository *repository.LinkRepository
-	UserRepository *repository.UserRepository
-	LinkProducer   *messaging.LinkProducer
+	DB                 *gorm.DB
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	LinkRepository     *repository.LinkRepository
+	UserRepository     *repository.UserRepository
+	LinkProducer       *messaging.LinkProducer
+	ShortCodeGenerator ShortCodeGenerator
+	ShortCodeAttempts  int
+	AliasPolicy        *AliasPolicy
+	CursorCodec        *CursorCodec
+	// RequireVerifiedEmail blocks link creation until the owner verified their email
+	RequireVerifiedEmail bool
+	LinkQuota            *LinkQuota
+	TagUseCase           *TagUseCase
 }
 
 func NewLinkUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
-	linkRepository *repository.LinkRepository, userRepository *repository.UserRepository, linkProducer *messaging.LinkProducer) *LinkUseCase {
+	linkRepository *repository.LinkRepository, userRepository *repository.UserRepository, linkProducer *messaging.LinkProducer,
+	shortCodeGenerator ShortCodeGenerator, shortCodeAttempts int, aliasPolicy *AliasPolicy, cursorCodec *CursorCodec,
+	requireVerifiedEmail bool, linkQuota *LinkQuota, tagUseCase *TagUseCase) *LinkUseCase {
+	if shortCodeAttempts <= 0 {
+		shortCodeAttempts = 1
+	}
 	return &LinkUseCase{
-		DB:             db,
-		Log:            logger,
-		Validate:       validate,
-		LinkRepository: linkRepository,
-		UserRepository: userRepository,
-		LinkProducer:   linkProducer,
+		DB:                   db,
+		Log:                  logger,
+		Validate:             validate,
+		LinkRepository:       linkRepository,
+		UserRepository:       userRepository,
+		LinkProducer:         linkProducer,
+		ShortCodeGenerator:   shortCodeGenerator,
+		ShortCodeAttempts:    shortCodeAttempts,
+		AliasPolicy:          aliasPolicy,
+		CursorCodec:          cursorCodec,
+		RequireVerifiedEmail: requireVerifiedEmail,
+		LinkQuota:            linkQuota,
+		TagUseCase:           tagUseCase,
 	}
 }
 
@@ -41,29 +62,71 @@ func (c *LinkUseCase) Create(ctx context.Context, request *model.CreateLinkReque
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
+	request.Tags = normalizeTagNames(request.Tags)
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.WithError(err).Error("failed to validate request body")
-		return nil, fiber.ErrBadRequest
+		return nil, c.validationError(err, request.ShortUrl)
+	}
+
+	if err := validateExpiry(request.ExpiresAt); err != nil {
+		c.Log.WithError(err).Error("invalid link expiry")
+		return nil, err
 	}
 
+	// the lock makes concurrent creates of one user wait for each other's quota check
 	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.UserId); err != nil {
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, request.UserId); err != nil {
 		c.Log.WithError(err).Error("failed to find user")
 		return nil, fiber.ErrNotFound
 	}
 
+	if c.RequireVerifiedEmail && user.VerifiedAt == nil {
+		c.Log.Warnf("User %s has no verified email", user.ID)
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := c.LinkQuota.Check(tx, user); err != nil {
+		c.Log.WithError(err).Warn("link quota exceeded")
+		return nil, err
+	}
+
 	link := &entity.Link{
-		ID:       uuid.NewString(),
-		UserId:   user.ID,
-		Title:    request.Title,
-		ShortUrl: request.ShortUrl,
-		LongUrl:  request.LongUrl,
-		IsActive: request.IsActive,
+		ID:           uuid.NewString(),
+		UserId:       user.ID,
+		Title:        request.Title,
+		ShortUrl:     c.AliasPolicy.Normalize(request.ShortUrl),
+		LongUrl:      request.LongUrl,
+		RedirectType: redirectTypeOrDefault(request.RedirectType),
+		IsActive:     request.IsActive,
+		ExpiresAt:    request.ExpiresAt,
+		MaxClicks:    request.MaxClicks,
 	}
 
-	if err := c.LinkRepository.Create(tx, link); err != nil {
+	if request.Password != "" {
+		password, err := c.hashPassword(request.Password)
+		if err != nil {
+			return nil, err
+		}
+		link.Password = password
+	}
+
+	if link.ShortUrl == "" {
+		if err := c.createWithGeneratedShortUrl(tx, link); err != nil {
+			return nil, err
+		}
+	} else if err := c.LinkRepository.Create(tx, link); err != nil {
 		c.Log.WithError(err).Error("failed to create link")
-		return nil, fiber.ErrInternalServerError
+		return nil, duplicateLinkError(err)
+	}
+
+	if err := c.LinkQuota.Record(tx, user); err != nil {
+		return nil, err
+	}
+
+	if len(request.Tags) > 0 {
+		if err := c.replaceTags(tx, link, request.Tags); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -87,7 +150,8 @@ func (c *LinkUseCase) Create(ctx context.Context, request *model.CreateLinkReque
 
 func (c *LinkUseCase) Get(ctx context.Context, req *model.GetLinkRequest) (*model.LinkResponse, error) {
 	link := new(entity.Link)
-	if err := c.LinkRepository.FindByIdAndUserId(c.DB.WithContext(ctx), link, req.ID, req.UserId); err != nil {
+	db := c.DB.WithContext(ctx).Scopes(c.LinkRepository.WithTags)
+	if err := c.LinkRepository.FindByIdAndUserId(db, link, req.ID, req.UserId); err != nil {
 		c.Log.WithError(err).Error("failed to find link")
 		return nil, fiber.ErrNotFound
 	}
@@ -95,11 +159,47 @@ func (c *LinkUseCase) Get(ctx context.Context, req *model.GetLinkRequest) (*mode
 	return converter.LinkToResponse(link), nil
 }
 
-func (c *LinkUseCase) List(ctx context.Context, request *model.ListLinkRequest) ([]model.LinkResponse, error) {
-	links, err := c.LinkRepository.FindAllByUserId(c.DB.WithContext(ctx), request.UserId)
+func (c *LinkUseCase) List(ctx context.Context, request *model.ListLinkRequest) ([]model.LinkResponse, int64, error) {
+	request.Tags = normalizeTagNames(request.Tags)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request")
+		return nil, 0, NewValidationError(err)
+	}
+
+	links, total, err := c.LinkRepository.Search(c.DB.WithContext(ctx), request)
 	if err != nil {
-		c.Log.WithError(err).Error("failed to find links by user id")
-		return nil, fiber.ErrInternalServerError
+		c.Log.WithError(err).Error("failed to search links")
+		return nil, 0, fiber.ErrInternalServerError
+	}
+
+	responses := make([]model.LinkResponse, len(links))
+	for i, link := range links {
+		responses[i] = *converter.LinkToResponse(&link)
+	}
+
+	return responses, total, nil
+}
+
+func (c *LinkUseCase) ListByCursor(ctx context.Context, request *model.ListLinkRequest) ([]model.LinkResponse, *model.CursorMetadata, error) {
+	request.Tags = normalizeTagNames(request.Tags)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Error("failed to validate request")
+		return nil, nil, NewValidationError(err)
+	}
+
+	var cursor *model.LinkCursor
+	if request.Cursor != "" {
+		cursor = new(model.LinkCursor)
+		if err := c.CursorCodec.Decode(request.Cursor, cursor); err != nil {
+			c.Log.WithError(err).Warn("failed to decode cursor")
+			return nil, nil, NewAppError(fiber.StatusBadRequest, "invalid_cursor", "Invalid cursor")
+		}
+	}
+
+	links, hasMore, err := c.LinkRepository.SearchByCursor(c.DB.WithContext(ctx), request, cursor)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to search links by cursor")
+		return nil, nil, fiber.ErrInternalServerError
 	}
 
 	responses := make([]model.LinkResponse, len(links))
@@ -107,16 +207,47 @@ func (c *LinkUseCase) List(ctx context.Context, request *model.ListLinkRequest)
 		responses[i] = *converter.LinkToResponse(&link)
 	}
 
-	return responses, nil
+	metadata := &model.CursorMetadata{Size: request.Size}
+	if len(links) == 0 {
+		return responses, metadata, nil
+	}
+
+	backward := cursor != nil && cursor.Backward
+	first, last := links[0], links[len(links)-1]
+
+	// a backward page was reached from a newer page, so older links always follow
+	if hasMore || backward {
+		if metadata.NextCursor, err = c.CursorCodec.Encode(&model.LinkCursor{CreatedAt: last.CreatedAt, ID: last.ID}); err != nil {
+			c.Log.WithError(err).Error("failed to encode cursor")
+			return nil, nil, fiber.ErrInternalServerError
+		}
+	}
+	if (backward && hasMore) || (!backward && cursor != nil) {
+		if metadata.PrevCursor, err = c.CursorCodec.Encode(&model.LinkCursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true}); err != nil {
+			c.Log.WithError(err).Error("failed to encode cursor")
+			return nil, nil, fiber.ErrInternalServerError
+		}
+	}
+
+	return responses, metadata, nil
 }
 
 func (c *LinkUseCase) Update(ctx context.Context, request *model.UpdateLinkRequest) (*model.LinkResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
+	if request.Tags != nil {
+		tags := normalizeTagNames(*request.Tags)
+		request.Tags = &tags
+	}
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.WithError(err).Error("failed to validate request body")
-		return nil, fiber.ErrBadRequest
+		return nil, c.validationError(err, request.ShortUrl)
+	}
+
+	if err := validateExpiry(request.ExpiresAt); err != nil {
+		c.Log.WithError(err).Error("invalid link expiry")
+		return nil, err
 	}
 
 	link := new(entity.Link)
@@ -125,14 +256,46 @@ func (c *LinkUseCase) Update(ctx context.Context, request *model.UpdateLinkReque
 		return nil, fiber.ErrNotFound
 	}
 
+	// a kept short url may be a generated code the alias policy would not
+	// accept as it is, so only a new one is checked and normalized
+	if request.ShortUrl != link.ShortUrl {
+		if err := c.AliasPolicy.Check(request.ShortUrl); err != nil {
+			c.Log.WithError(err).Error("invalid short url")
+			return nil, aliasError(err)
+		}
+		link.ShortUrl = c.AliasPolicy.Normalize(request.ShortUrl)
+	}
+
 	link.Title = request.Title
-	link.ShortUrl = request.ShortUrl
 	link.LongUrl = request.LongUrl
+	link.RedirectType = redirectTypeOrDefault(request.RedirectType)
 	link.IsActive = *request.IsActive
+	link.ExpiresAt = request.ExpiresAt
+	link.MaxClicks = request.MaxClicks
 	link.UpdatedAt = time.Now().UnixMilli()
 
-	if err := c.LinkRepository.Update(tx, link); err != nil {
+	if request.Password != nil {
+		link.Password = nil
+		if *request.Password != "" {
+			password, err := c.hashPassword(*request.Password)
+			if err != nil {
+				return nil, err
+			}
+			link.Password = password
+		}
+	}
+
+	if err := c.LinkRepository.UpdateSettings(tx, link); err != nil {
 		c.Log.WithError(err).Error("failed to update link")
+		return nil, duplicateLinkError(err)
+	}
+
+	if request.Tags != nil {
+		if err := c.replaceTags(tx, link, *request.Tags); err != nil {
+			return nil, err
+		}
+	} else if err := c.LinkRepository.FindTags(tx, link); err != nil {
+		c.Log.WithError(err).Error("failed to find link tags")
 		return nil, fiber.ErrInternalServerError
 	}
 
@@ -176,4 +339,114 @@ func (c *LinkUseCase) Delete(ctx context.Context, request *model.DeleteLinkReque
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// replaceTags makes the tags named names the tags of link, creating the ones
+// its owner does not have yet
+func (c *LinkUseCase) replaceTags(tx *gorm.DB, link *entity.Link, names []string) error {
+	tags, err := c.TagUseCase.Resolve(tx, link.UserId, names)
+	if err != nil {
+		return err
+	}
+	if err := c.LinkRepository.ReplaceTags(tx, link, tags); err != nil {
+		c.Log.WithError(err).Error("failed to replace link tags")
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// createWithGeneratedShortUrl inserts link with a server generated short url,
+// asking the generator for a new candidate whenever the previous one collides
+// with an existing link.
+func (c *LinkUseCase) createWithGeneratedShortUrl(tx *gorm.DB, link *entity.Link) error {
+	for attempt := 0; attempt < c.ShortCodeAttempts; attempt++ {
+		code, err := c.ShortCodeGenerator.Generate(tx, link.LongUrl, attempt)
+		if err != nil {
+			c.Log.WithError(err).Error("failed to generate short url")
+			return fiber.ErrInternalServerError
+		}
+
+		// generated codes must not take a reserved route either
+		if err := c.AliasPolicy.Check(code); err != nil {
+			c.Log.Warnf("generated short url %s is not allowed, attempt %d: %v", code, attempt+1, err)
+			continue
+		}
+
+		link.ShortUrl = code
+		err = c.LinkRepository.Create(tx, link)
+		if err == nil {
+			return nil
+		}
+
+		if !repository.IsDuplicateKey(err, "short_url") {
+			c.Log.WithError(err).Error("failed to create link")
+			return duplicateLinkError(err)
+		}
+		c.Log.Warnf("generated short url %s is already taken, attempt %d", code, attempt+1)
+	}
+
+	c.Log.Errorf("failed to allocate a short url after %d attempts", c.ShortCodeAttempts)
+	return NewAppError(fiber.StatusConflict, "short_url_unavailable", "Could not allocate a unique short url, please retry")
+}
+
+// validationError explains alias failures with the rule the alias broke,
+// which clients can not infer from the request shape alone
+func (c *LinkUseCase) validationError(err error, shortUrl string) error {
+	appErr := NewValidationError(err)
+	for i, detail := range appErr.Details {
+		if detail.Code != "alias" {
+			continue
+		}
+		if aliasErr := c.AliasPolicy.Check(shortUrl); aliasErr != nil {
+			appErr.Message = aliasErr.Error()
+			appErr.Details[i].Message = aliasErr.Error()
+		}
+	}
+	return appErr
+}
+
+// aliasError rejects a short url the alias policy does not accept the way
+// a failed alias validation does
+func aliasError(err error) *AppError {
+	appErr := NewAppError(fiber.StatusBadRequest, "invalid_request", err.Error())
+	appErr.Details = []model.FieldError{{Field: "short_url", Code: "alias", Message: err.Error()}}
+	return appErr
+}
+
+// duplicateLinkError maps a failed insert or update of a link to a conflict
+// when it violated one of the unique indexes
+func duplicateLinkError(err error) error {
+	if repository.IsDuplicateKey(err, "short_url") {
+		return NewAppError(fiber.StatusConflict, "short_url_taken", "Short url is already taken")
+	}
+	if repository.IsDuplicateKey(err, "") {
+		return fiber.ErrConflict
+	}
+	return fiber.ErrInternalServerError
+}
+
+func (c *LinkUseCase) hashPassword(password string) (*string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		c.Log.WithError(err).Error("failed to generate bcrypt hash")
+		return nil, fiber.ErrInternalServerError
+	}
+	result := string(hashed)
+	return &result, nil
+}
+
+func validateExpiry(expiresAt *int64) error {
+	if expiresAt != nil && *expiresAt <= time.Now().UnixMilli() {
+		return NewAppError(fiber.StatusBadRequest, "invalid_expiry", "Expiry must be in the future")
+	}
+	return nil
+}
+
+// redirectTypeOrDefault falls back to a temporary redirect when the client
+// did not pick one, so a link can always be repointed later.
+func redirectTypeOrDefault(redirectType int) int {
+	if redirectType == 0 {
+		return fiber.StatusFound
+	}
+	return redirectType
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"devshort-backend/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// LoginThrottle slows down password guessing. Failed logins are counted per
+// user id and per client IP; past the free attempts every further try has to
+// wait twice as long as the one before, up to MaxDelay.
+type LoginThrottle struct {
+	Log                    *logrus.Logger
+	LoginFailureRepository *repository.LoginFailureRepository
+	Clock                  Clock
+	IpHashSalt             string
+	FreeAttempts           int
+	IpFreeAttempts         int
+	BaseDelay              time.Duration
+	MaxDelay               time.Duration
+	// LockoutThreshold is the user failure count that locks the account
+	LockoutThreshold int
+	// ResetAfter forgets failures once none happened for that long
+	ResetAfter time.Duration
+}
+
+func NewLoginThrottle(logger *logrus.Logger, loginFailureRepository *repository.LoginFailureRepository, clock Clock,
+	ipHashSalt string, freeAttempts int, ipFreeAttempts int, baseDelay time.Duration, maxDelay time.Duration,
+	lockoutThreshold int, resetAfter time.Duration) *LoginThrottle {
+	return &LoginThrottle{
+		Log:                    logger,
+		LoginFailureRepository: loginFailureRepository,
+		Clock:                  clock,
+		IpHashSalt:             ipHashSalt,
+		FreeAttempts:           freeAttempts,
+		IpFreeAttempts:         ipFreeAttempts,
+		BaseDelay:              baseDelay,
+		MaxDelay:               maxDelay,
+		LockoutThreshold:       lockoutThreshold,
+		ResetAfter:             resetAfter,
+	}
+}
+
+func userSubject(userId string) string {
+	return "user:" + userId
+}
+
+func (t *LoginThrottle) ipSubject(ip string) string {
+	return "ip:" + hashIp(t.IpHashSalt, ip)
+}
+
+// Wait returns how long a login of userId from ip has to wait
+func (t *LoginThrottle) Wait(db *gorm.DB, userId string, ip string) (time.Duration, error) {
+	userKey, ipKey := userSubject(userId), t.ipSubject(ip)
+	failures, err := t.LoginFailureRepository.FindBySubjects(db, []string{userKey, ipKey})
+	if err != nil {
+		return 0, err
+	}
+
+	now := t.Clock.Now().UnixMilli()
+	var wait time.Duration
+	for _, failure := range failures {
+		if now-failure.LastFailedAt >= t.ResetAfter.Milliseconds() {
+			continue
+		}
+
+		free := t.FreeAttempts
+		if failure.Subject == ipKey {
+			free = t.IpFreeAttempts
+		}
+		until := failure.LastFailedAt + t.delay(failure.Failures, free).Milliseconds()
+		if remaining := time.Duration(until-now) * time.Millisecond; remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait, nil
+}
+
+// Fail counts a failed login of userId from ip and returns the failure count
+// of the user
+func (t *LoginThrottle) Fail(tx *gorm.DB, userId string, ip string) (int, error) {
+	now := t.Clock.Now().UnixMilli()
+	resetBefore := now - t.ResetAfter.Milliseconds()
+
+	if _, err := t.LoginFailureRepository.Increment(tx, t.ipSubject(ip), now, resetBefore); err != nil {
+		return 0, err
+	}
+	return t.LoginFailureRepository.Increment(tx, userSubject(userId), now, resetBefore)
+}
+
+// Reset forgets the failures of userId after a successful login
+func (t *LoginThrottle) Reset(tx *gorm.DB, userId string) error {
+	return t.LoginFailureRepository.DeleteBySubject(tx, userSubject(userId))
+}
+
+// ShouldLock reports whether failures user failures lock the account
+func (t *LoginThrottle) ShouldLock(failures int) bool {
+	return t.LockoutThreshold > 0 && failures >= t.LockoutThreshold
+}
+
+// delay is zero for the free attempts, then doubles with every failure
+func (t *LoginThrottle) delay(failures int, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+
+	delay := t.BaseDelay
+	for i := free; i < failures && delay < t.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, t.MaxDelay)
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var errInvalidMfaCode = NewAppError(fiber.StatusBadRequest, "invalid_mfa_code", "Invalid two factor code")
+
+// MfaUseCase manages TOTP two factor authentication. A login of an enrolled
+// user gets a challenge token first, which Redeem trades for the user once a
+// TOTP or recovery code is given.
+type MfaUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	UserRepository         *repository.UserRepository
+	UserTokenRepository    *repository.UserTokenRepository
+	RecoveryCodeRepository *repository.RecoveryCodeRepository
+	// Issuer names the account in authenticator apps
+	Issuer            string
+	ChallengeTTL      time.Duration
+	RecoveryCodeCount int
+}
+
+func NewMfaUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	userRepository *repository.UserRepository, userTokenRepository *repository.UserTokenRepository,
+	recoveryCodeRepository *repository.RecoveryCodeRepository,
+	issuer string, challengeTTL time.Duration, recoveryCodeCount int) *MfaUseCase {
+	return &MfaUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		UserRepository:         userRepository,
+		UserTokenRepository:    userTokenRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		Issuer:                 issuer,
+		ChallengeTTL:           challengeTTL,
+		RecoveryCodeCount:      recoveryCodeCount,
+	}
+}
+
+// Enroll generates a new TOTP secret. Two factor login only starts once the
+// secret is confirmed with a code, so an abandoned enrollment locks nobody out.
+func (c *MfaUseCase) Enroll(ctx context.Context, request *model.EnrollTotpRequest) (*model.TotpEnrollmentResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.TotpEnabledAt != nil {
+		return nil, NewAppError(fiber.StatusConflict, "mfa_already_enabled", "Two factor authentication is already enabled")
+	}
+
+	secret, err := newTotpSecret()
+	if err != nil {
+		c.Log.Warnf("Failed to generate totp secret : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	user.TotpSecret = &secret
+	user.TotpLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.TotpEnrollmentResponse{
+		Secret: secret,
+		Uri:    totpUri(c.Issuer, user.ID, secret),
+	}, nil
+}
+
+// Confirm turns two factor login on and returns the recovery codes, which are
+// only shown this once
+func (c *MfaUseCase) Confirm(ctx context.Context, request *model.ConfirmTotpRequest) (*model.RecoveryCodesResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	if user.TotpEnabledAt != nil {
+		return nil, NewAppError(fiber.StatusConflict, "mfa_already_enabled", "Two factor authentication is already enabled")
+	}
+	if user.TotpSecret == nil {
+		return nil, NewAppError(fiber.StatusBadRequest, "mfa_not_enrolled", "Two factor authentication is not enrolled")
+	}
+
+	now := time.Now()
+	step, ok := verifyTotp(*user.TotpSecret, request.Code, now, user.TotpLastStep)
+	if !ok {
+		c.Log.Warnf("Invalid totp code confirming user %s", user.ID)
+		return nil, errInvalidMfaCode
+	}
+
+	enabledAt := now.UnixMilli()
+	user.TotpEnabledAt = &enabledAt
+	user.TotpLastStep = step
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	codes, err := c.replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s enabled two factor authentication", user.ID)
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two factor login off, proven with a TOTP or recovery code
+func (c *MfaUseCase) Disable(ctx context.Context, request *model.DisableTotpRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, request.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return fiber.ErrNotFound
+	}
+
+	if user.TotpEnabledAt == nil {
+		return NewAppError(fiber.StatusBadRequest, "mfa_not_enabled", "Two factor authentication is not enabled")
+	}
+
+	ok, err := c.verifySecondFactor(tx, user, request.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.Log.Warnf("Invalid two factor code disabling user %s", user.ID)
+		return errInvalidMfaCode
+	}
+
+	user.TotpSecret = nil
+	user.TotpEnabledAt = nil
+	user.TotpLastStep = 0
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.RecoveryCodeRepository.DeleteByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s disabled two factor authentication", user.ID)
+	return nil
+}
+
+// Challenge stores a single use token inside tx that completes the login of
+// user with a second factor
+func (c *MfaUseCase) Challenge(tx *gorm.DB, user *entity.User) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		c.Log.Warnf("Failed to generate mfa token : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+
+	record := &entity.UserToken{
+		ID:        uuid.NewString(),
+		UserId:    user.ID,
+		Purpose:   entity.UserTokenMfaChallenge,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: time.Now().Add(c.ChallengeTTL).UnixMilli(),
+	}
+	if err := c.UserTokenRepository.Create(tx, record); err != nil {
+		c.Log.Warnf("Failed create mfa token : %+v", err)
+		return "", fiber.ErrInternalServerError
+	}
+	return token, nil
+}
+
+// Redeem checks code against the user of the challenge token inside tx. The
+// token is spent either way, so every guess costs a password login; on a
+// wrong code ok is false and the caller still has to commit tx.
+func (c *MfaUseCase) Redeem(tx *gorm.DB, mfaToken string, code string) (user *entity.User, ok bool, err error) {
+	token := new(entity.UserToken)
+	if err := c.UserTokenRepository.FindByTokenHash(tx, token, entity.UserTokenMfaChallenge, hashOpaqueToken(mfaToken)); err != nil {
+		c.Log.Warnf("Failed find mfa token : %+v", err)
+		return nil, false, fiber.ErrUnauthorized
+	}
+
+	now := time.Now().UnixMilli()
+	if token.UsedAt != nil || token.ExpiresAt <= now {
+		c.Log.Warnf("Mfa token %s is no longer valid", token.ID)
+		return nil, false, fiber.ErrUnauthorized
+	}
+
+	token.UsedAt = &now
+	if err := c.UserTokenRepository.Update(tx, token); err != nil {
+		c.Log.Warnf("Failed save mfa token : %+v", err)
+		return nil, false, fiber.ErrInternalServerError
+	}
+
+	user = new(entity.User)
+	if err := c.UserRepository.FindByIdForUpdate(tx, user, token.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, false, fiber.ErrUnauthorized
+	}
+
+	ok, err = c.verifySecondFactor(tx, user, code)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		c.Log.Warnf("Invalid two factor code for user %s", user.ID)
+	}
+	return user, ok, nil
+}
+
+// verifySecondFactor accepts a TOTP code or burns a matching recovery code.
+// The user row must be locked by tx.
+func (c *MfaUseCase) verifySecondFactor(tx *gorm.DB, user *entity.User, code string) (bool, error) {
+	if user.TotpSecret == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	if len(code) == totpDigits {
+		step, ok := verifyTotp(*user.TotpSecret, code, now, user.TotpLastStep)
+		if !ok {
+			return false, nil
+		}
+		user.TotpLastStep = step
+		if err := c.UserRepository.Update(tx, user); err != nil {
+			c.Log.Warnf("Failed save user : %+v", err)
+			return false, fiber.ErrInternalServerError
+		}
+		return true, nil
+	}
+
+	used, err := c.RecoveryCodeRepository.Use(tx, user.ID, hashOpaqueToken(normalizeRecoveryCode(code)), now.UnixMilli())
+	if err != nil {
+		c.Log.Warnf("Failed use recovery code : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	if used {
+		c.Log.Infof("User %s used a recovery code", user.ID)
+	}
+	return used, nil
+}
+
+func (c *MfaUseCase) replaceRecoveryCodes(tx *gorm.DB, userId string) ([]string, error) {
+	if err := c.RecoveryCodeRepository.DeleteByUserId(tx, userId); err != nil {
+		c.Log.Warnf("Failed delete recovery codes : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	codes := make([]string, c.RecoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			c.Log.Warnf("Failed to generate recovery code : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		record := &entity.RecoveryCode{
+			ID:       uuid.NewString(),
+			UserId:   userId,
+			CodeHash: hashOpaqueToken(code),
+		}
+		if err := c.RecoveryCodeRepository.Create(tx, record); err != nil {
+			c.Log.Warnf("Failed create recovery code : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"devshort-backend/internal/model"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PasswordPolicy decides which passwords users may set
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DisallowUserInfo rejects passwords containing the user id or a part of the name
+	DisallowUserInfo bool
+	// Breached is checked last and may be nil
+	Breached *BreachedPasswords
+}
+
+// Check returns an *AppError listing every rule password broke, so clients
+// can show them all at once.
+// userInfo holds the id and name of the account the password is for.
+func (p *PasswordPolicy) Check(password string, userInfo ...string) error {
+	var violations []model.FieldError
+	violate := func(code string, message string) {
+		violations = append(violations, model.FieldError{Field: "password", Code: code, Message: message})
+	}
+
+	if length := utf8.RuneCountInString(password); length < p.MinLength {
+		violate("password_too_short", fmt.Sprintf("Password must be at least %d characters", p.MinLength))
+	}
+	// bcrypt only looks at the first 72 bytes
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		violate("password_too_long", fmt.Sprintf("Password must be at most %d bytes", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violate("password_missing_upper", "Password must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		violate("password_missing_lower", "Password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violate("password_missing_digit", "Password must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violate("password_missing_symbol", "Password must contain a symbol")
+	}
+
+	if p.DisallowUserInfo && containsUserInfo(password, userInfo) {
+		violate("password_contains_user_info", "Password must not contain your user id or name")
+	}
+
+	if len(violations) == 0 && p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violate("password_breached", "Password appears in a list of breached passwords, choose another one")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &AppError{
+			Status:  fiber.StatusBadRequest,
+			Code:    "invalid_password",
+			Message: "Password does not meet the password policy",
+			Details: violations,
+		}
+	}
+	return nil
+}
+
+// containsUserInfo matches the user infos and the words in them, ignoring
+// case and words too short to be meaningful
+func containsUserInfo(password string, userInfo []string) bool {
+	password = strings.ToLower(password)
+	for _, info := range userInfo {
+		for _, word := range append(strings.Fields(info), info) {
+			word = strings.ToLower(word)
+			if utf8.RuneCountInString(word) >= 3 && strings.Contains(password, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/gateway/mail"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type PasswordResetUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	UserRepository      *repository.UserRepository
+	UserTokenRepository *repository.UserTokenRepository
+	ApiKeyRepository    *repository.ApiKeyRepository
+	SessionUseCase      *SessionUseCase
+	TokenRevoker        *TokenRevoker
+	MailSender          mail.Sender
+	PasswordPolicy      *PasswordPolicy
+	// ResetUrl is the page that reads the token from its query and confirms the reset
+	ResetUrl       string
+	TokenTTL       time.Duration
+	AccessTokenTTL time.Duration
+}
+
+func NewPasswordResetUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	userRepository *repository.UserRepository, userTokenRepository *repository.UserTokenRepository,
+	apiKeyRepository *repository.ApiKeyRepository,
+	sessionUseCase *SessionUseCase, tokenRevoker *TokenRevoker, mailSender mail.Sender, passwordPolicy *PasswordPolicy,
+	resetUrl string, tokenTTL time.Duration, accessTokenTTL time.Duration) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validate:            validate,
+		UserRepository:      userRepository,
+		UserTokenRepository: userTokenRepository,
+		ApiKeyRepository:    apiKeyRepository,
+		SessionUseCase:      sessionUseCase,
+		TokenRevoker:        tokenRevoker,
+		MailSender:          mailSender,
+		PasswordPolicy:      passwordPolicy,
+		ResetUrl:            resetUrl,
+		TokenTTL:            tokenTTL,
+		AccessTokenTTL:      accessTokenTTL,
+	}
+}
+
+// Request mails a reset link to the owner of the email. Only verified
+// addresses get one, anyone could have typed in an unverified one. It succeeds
+// either way, so the endpoint can not be used to probe accounts. A new request
+// replaces any earlier reset link.
+func (c *PasswordResetUseCase) Request(ctx context.Context, request *model.RequestPasswordResetRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindByEmail(tx, user, *normalizeEmail(request.Email)); err != nil {
+		c.Log.Warnf("No user for password reset : %+v", err)
+		return nil
+	}
+	if user.VerifiedAt == nil {
+		c.Log.Warnf("User %s asked for a password reset to an unverified email", user.ID)
+		return nil
+	}
+
+	now := time.Now()
+	if err := c.UserTokenRepository.MarkUsedByUserId(tx, user.ID, entity.UserTokenPasswordReset, now.UnixMilli()); err != nil {
+		c.Log.Warnf("Failed burn previous reset tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		c.Log.Warnf("Failed to generate reset token : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	record := &entity.UserToken{
+		ID:        uuid.NewString(),
+		UserId:    user.ID,
+		Purpose:   entity.UserTokenPasswordReset,
+		TokenHash: hashOpaqueToken(token),
+		ExpiresAt: now.Add(c.TokenTTL).UnixMilli(),
+	}
+	if err := c.UserTokenRepository.Create(tx, record); err != nil {
+		c.Log.Warnf("Failed create reset token : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	message := &mail.Message{
+		To:      *user.Email,
+		Subject: "Reset your DevShort password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password:\n\n%s?token=%s\n\n"+
+			"The link works once and expires in %d minutes. If you did not ask for it, ignore this mail.\n",
+			user.Name, c.ResetUrl, url.QueryEscape(token), int(c.TokenTTL.Minutes())),
+	}
+	if err := c.MailSender.Send(ctx, message); err != nil {
+		// the answer must not depend on the account, the failure is only logged
+		c.Log.Warnf("Failed send password reset mail : %+v", err)
+	}
+
+	return nil
+}
+
+// Confirm sets a new password with a reset token. Every session, access token
+// and API key of the user is revoked, since whoever held the old password is
+// out and could have created keys with it.
+func (c *PasswordResetUseCase) Confirm(ctx context.Context, request *model.ConfirmPasswordResetRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	token := new(entity.UserToken)
+	if err := c.UserTokenRepository.FindByTokenHash(tx, token, entity.UserTokenPasswordReset, hashOpaqueToken(request.Token)); err != nil {
+		c.Log.Warnf("Failed find reset token : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	now := time.Now().UnixMilli()
+	if token.UsedAt != nil || token.ExpiresAt <= now {
+		c.Log.Warnf("Reset token %s is no longer valid", token.ID)
+		return fiber.ErrBadRequest
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, token.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	if err := c.PasswordPolicy.Check(request.Password, user.ID, user.Name); err != nil {
+		c.Log.Warnf("Password rejected by policy : %+v", err)
+		return err
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	user.Password = string(password)
+	// proving access to the mailbox also lifts a login lockout
+	user.LockedUntil = nil
+
+	if err := c.UserRepository.Update(tx, user); err != nil {
+		c.Log.Warnf("Failed save user : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.UserTokenRepository.MarkUsedByUserId(tx, user.ID, entity.UserTokenPasswordReset, now); err != nil {
+		c.Log.Warnf("Failed burn reset tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.TokenRevoker.RevokeUser(tx, user.ID, now, c.AccessTokenTTL); err != nil {
+		c.Log.Warnf("Failed revoke user tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.SessionUseCase.EndAll(tx, user.ID); err != nil {
+		return err
+	}
+
+	if err := c.ApiKeyRepository.DeleteByUserId(tx, user.ID); err != nil {
+		c.Log.Warnf("Failed delete api keys : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s reset their password", user.ID)
+	return nil
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimit allows Limit requests per Window. Requests are spent from a
+// token bucket holding Limit tokens that refills evenly over Window, so short
+// bursts are fine as long as the average stays under the limit.
+type RateLimit struct {
+	// Name keeps the buckets of different route groups apart
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, zero when allowed
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the buckets. The in-process store only sees the
+// requests of one instance; a shared backend has to implement Take atomically.
+type RateLimitStore interface {
+	// Take spends one request of limit on key at now
+	Take(ctx context.Context, key string, limit *RateLimit, now time.Time) (*RateLimitResult, error)
+}
+
+type RateLimiter struct {
+	Log   *logrus.Logger
+	Store RateLimitStore
+	Clock Clock
+}
+
+func NewRateLimiter(logger *logrus.Logger, store RateLimitStore, clock Clock) *RateLimiter {
+	return &RateLimiter{
+		Log:   logger,
+		Store: store,
+		Clock: clock,
+	}
+}
+
+// Take spends one request of limit for subject and returns an *AppError
+// once it is used up. When the store fails the request is let through, the
+// result is nil then.
+func (r *RateLimiter) Take(ctx context.Context, limit *RateLimit, subject string) (*RateLimitResult, error) {
+	result, err := r.Store.Take(ctx, limit.Name+":"+subject, limit, r.Clock.Now())
+	if err != nil {
+		r.Log.Warnf("Failed take rate limit : %+v", err)
+		return nil, nil
+	}
+	if result.Allowed {
+		return result, nil
+	}
+	return result, &AppError{
+		Status:     fiber.StatusTooManyRequests,
+		Code:       "rate_limited",
+		Message:    "Too many requests, try again later",
+		RetryAfter: result.RetryAfter,
+	}
+}
+
+// MemoryRateLimitStore is a RateLimitStore inside this process
+type MemoryRateLimitStore struct {
+	mutex   sync.Mutex
+	buckets map[string]*rateLimitBucket
+	takes   int
+}
+
+type rateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	window    time.Duration
+}
+
+// sweepEvery is how many takes pass between dropping full buckets
+const sweepEvery = 1000
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: map[string]*rateLimitBucket{},
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit *RateLimit, now time.Time) (*RateLimitResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	capacity := float64(limit.Limit)
+	perToken := limit.Window / time.Duration(limit.Limit)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.window = limit.Window
+	if elapsed := now.Sub(bucket.updatedAt); elapsed > 0 {
+		bucket.tokens = math.Min(capacity, bucket.tokens+float64(elapsed)/float64(perToken))
+		bucket.updatedAt = now
+	}
+
+	result := &RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - bucket.tokens) * float64(perToken))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration((capacity - bucket.tokens) * float64(perToken))
+	return result, nil
+}
+
+// sweep drops buckets that have refilled, they are the same as no bucket
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) >= bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+}
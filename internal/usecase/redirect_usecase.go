@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/gateway/messaging"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrLinkExpired is returned for links past their expiry time or click limit,
+// so delivery can send visitors to a fallback page instead
+var ErrLinkExpired = NewAppError(fiber.StatusGone, "link_expired", "Link has expired")
+
+// ErrLinkLocked is returned for password protected links visited without a
+// valid unlock token, so delivery can show the password form
+var ErrLinkLocked = NewAppError(fiber.StatusUnauthorized, "link_locked", "Link is password protected")
+
+type RedirectUseCase struct {
+	DB             *gorm.DB
+	Log            *logrus.Logger
+	Validate       *validator.Validate
+	LinkRepository *repository.LinkRepository
+	AliasPolicy    *AliasPolicy
+	ClickProducer  *messaging.ClickProducer
+	IpHashSalt     string
+	LinkUnlocker   *LinkUnlocker
+}
+
+func NewRedirectUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	linkRepository *repository.LinkRepository, aliasPolicy *AliasPolicy,
+	clickProducer *messaging.ClickProducer, ipHashSalt string, linkUnlocker *LinkUnlocker) *RedirectUseCase {
+	return &RedirectUseCase{
+		DB:             db,
+		Log:            logger,
+		Validate:       validate,
+		LinkRepository: linkRepository,
+		AliasPolicy:    aliasPolicy,
+		ClickProducer:  clickProducer,
+		IpHashSalt:     ipHashSalt,
+		LinkUnlocker:   linkUnlocker,
+	}
+}
+
+func (c *RedirectUseCase) Resolve(ctx context.Context, request *model.ResolveLinkRequest) (*model.RedirectResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("invalid short url")
+		return nil, fiber.ErrNotFound
+	}
+
+	link, err := c.findAvailable(ctx, request.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.Password != nil && !c.LinkUnlocker.Verify(link, request.UnlockToken, time.Now()) {
+		c.Log.Infof("link %s is locked", link.ID)
+		return nil, ErrLinkLocked
+	}
+
+	if err := c.visit(ctx, link, request); err != nil {
+		return nil, err
+	}
+
+	return converter.LinkToRedirectResponse(link), nil
+}
+
+// Unlock checks the password of a protected link and hands out a token that
+// lets the visitor through until it expires. The unlock itself counts as a visit.
+func (c *RedirectUseCase) Unlock(ctx context.Context, request *model.UnlockLinkRequest) (*model.UnlockLinkResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithError(err).Warn("invalid unlock request")
+		return nil, NewValidationError(err)
+	}
+
+	link, err := c.findAvailable(ctx, request.ShortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.Password == nil {
+		c.Log.Infof("link %s has no password", link.ID)
+		return nil, NewAppError(fiber.StatusBadRequest, "link_not_protected", "Link is not password protected")
+	}
+
+	now := time.Now()
+	client := hashIp(c.IpHashSalt, request.IpAddress)
+	if wait := c.LinkUnlocker.Blocked(link.ID, client, now); wait > 0 {
+		c.Log.Warnf("too many password attempts for link %s", link.ID)
+		return nil, &AppError{
+			Status:     fiber.StatusTooManyRequests,
+			Code:       "too_many_password_attempts",
+			Message:    "Too many password attempts, try again later",
+			RetryAfter: wait,
+		}
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(*link.Password), []byte(request.Password)); err != nil {
+		c.Log.Warnf("Invalid link password : %+v", err)
+		c.LinkUnlocker.Fail(link.ID, client, now)
+		return nil, NewAppError(fiber.StatusUnauthorized, "wrong_password", "Wrong password")
+	}
+	c.LinkUnlocker.Reset(link.ID, client)
+
+	if err := c.visit(ctx, link, &request.ResolveLinkRequest); err != nil {
+		return nil, err
+	}
+
+	token, expiresAt := c.LinkUnlocker.Sign(link, now)
+	return &model.UnlockLinkResponse{
+		LongUrl:      link.LongUrl,
+		RedirectType: link.RedirectType,
+		Token:        token,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// findAvailable loads the link behind a short url and rejects links that can
+// no longer be visited
+func (c *RedirectUseCase) findAvailable(ctx context.Context, shortUrl string) (*entity.Link, error) {
+	link := new(entity.Link)
+	if err := c.findByShortUrl(c.DB.WithContext(ctx), link, shortUrl); err != nil {
+		c.Log.WithError(err).Warn("failed to find link by short url")
+		return nil, fiber.ErrNotFound
+	}
+
+	// expiry comes first, the expiry job deactivates expired links and they
+	// still have to be told apart from links their owner switched off
+	if link.ExpiresAt != nil && *link.ExpiresAt <= time.Now().UnixMilli() {
+		c.Log.Infof("link %s expired at %d", link.ID, *link.ExpiresAt)
+		return nil, ErrLinkExpired
+	}
+
+	if link.MaxClicks != nil && link.ClickCount >= *link.MaxClicks {
+		c.Log.Infof("link %s reached its click limit of %d", link.ID, *link.MaxClicks)
+		return nil, ErrLinkExpired
+	}
+
+	if !link.IsActive {
+		c.Log.Infof("link %s is not active", link.ID)
+		return nil, NewAppError(fiber.StatusGone, "link_inactive", "Link is no longer active")
+	}
+
+	return link, nil
+}
+
+// visit counts the click against the link's limit and publishes it
+func (c *RedirectUseCase) visit(ctx context.Context, link *entity.Link, request *model.ResolveLinkRequest) error {
+	// only links with a click limit pay for a write on every visit
+	if link.MaxClicks != nil {
+		counted, err := c.LinkRepository.IncrementClickCount(c.DB.WithContext(ctx), link)
+		if err != nil {
+			c.Log.WithError(err).Error("failed to count click")
+			return fiber.ErrInternalServerError
+		}
+		if !counted {
+			c.Log.Infof("link %s reached its click limit of %d", link.ID, *link.MaxClicks)
+			return ErrLinkExpired
+		}
+	}
+
+	c.publishClick(link, request)
+	return nil
+}
+
+// publishClick records the visit without delaying the redirect; a click that
+// can not be handed to the producer is logged and dropped.
+func (c *RedirectUseCase) publishClick(link *entity.Link, request *model.ResolveLinkRequest) {
+	if c.ClickProducer == nil {
+		c.Log.Debug("Kafka producer is disabled, skipping click event")
+		return
+	}
+
+	event := &model.ClickEvent{
+		ID:             uuid.NewString(),
+		LinkId:         link.ID,
+		ShortUrl:       link.ShortUrl,
+		Timestamp:      time.Now().UnixMilli(),
+		Referrer:       request.Referrer,
+		UserAgent:      request.UserAgent,
+		IpHash:         hashIp(c.IpHashSalt, request.IpAddress),
+		AcceptLanguage: request.AcceptLanguage,
+		Country:        request.Country,
+	}
+
+	if err := c.ClickProducer.Send(event); err != nil {
+		c.Log.WithError(err).Warn("failed to publish click event")
+	}
+}
+
+// hashIp keeps visitors distinguishable for unique counts without storing
+// their address
+func hashIp(salt string, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(salt + ip))
+	return hex.EncodeToString(sum[:])
+}
+
+// findByShortUrl matches generated codes exactly and falls back to the
+// normalized form, so case-insensitive aliases resolve however they are typed.
+func (c *RedirectUseCase) findByShortUrl(tx *gorm.DB, link *entity.Link, shortUrl string) error {
+	err := c.LinkRepository.FindByShortUrl(tx, link, shortUrl)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if normalized := c.AliasPolicy.Normalize(shortUrl); normalized != shortUrl {
+			return c.LinkRepository.FindByShortUrl(tx, link, normalized)
+		}
+	}
+	return err
+}
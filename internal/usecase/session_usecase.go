@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength matches the sessions.user_agent column
+const maxUserAgentLength = 255
+
+type SessionUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	SessionRepository      *repository.SessionRepository
+	RefreshTokenRepository *repository.RefreshTokenRepository
+	IpHashSalt             string
+	// TouchInterval is how stale last_seen_at may get. Requests inside it are
+	// answered from memory instead of writing to the sessions table.
+	TouchInterval time.Duration
+
+	mutex   sync.Mutex
+	touched map[string]int64
+}
+
+func NewSessionUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	sessionRepository *repository.SessionRepository, refreshTokenRepository *repository.RefreshTokenRepository,
+	ipHashSalt string, touchInterval time.Duration) *SessionUseCase {
+	return &SessionUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validate:               validate,
+		SessionRepository:      sessionRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		IpHashSalt:             ipHashSalt,
+		TouchInterval:          touchInterval,
+		touched:                map[string]int64{},
+	}
+}
+
+// Start records a new login of userId inside tx
+func (c *SessionUseCase) Start(tx *gorm.DB, userId string, userAgent string, ip string) (*entity.Session, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := &entity.Session{
+		ID:         uuid.NewString(),
+		UserId:     userId,
+		UserAgent:  userAgent,
+		IpHash:     hashIp(c.IpHashSalt, ip),
+		LastSeenAt: time.Now().UnixMilli(),
+	}
+	if err := c.SessionRepository.Create(tx, session); err != nil {
+		c.Log.Warnf("Failed create session : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	return session, nil
+}
+
+// Touch marks the session of auth as seen and fails once it was deleted. The
+// database is only written once per TouchInterval for each session, so a
+// session deleted by another instance may keep working for that long.
+func (c *SessionUseCase) Touch(ctx context.Context, auth *model.Auth) error {
+	now := time.Now().UnixMilli()
+
+	c.mutex.Lock()
+	touchedAt, ok := c.touched[auth.SessionId]
+	c.mutex.Unlock()
+	if ok && now-touchedAt < c.TouchInterval.Milliseconds() {
+		return nil
+	}
+
+	found, err := c.SessionRepository.Touch(c.DB.WithContext(ctx), auth.SessionId, auth.ID, now)
+	if err != nil {
+		c.Log.Warnf("Failed touch session : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	if !found {
+		c.forget(auth.SessionId)
+		c.Log.Warnf("Session %s of user %s was ended", auth.SessionId, auth.ID)
+		return fiber.ErrUnauthorized
+	}
+
+	c.mutex.Lock()
+	c.touched[auth.SessionId] = now
+	c.sweep(now)
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *SessionUseCase) List(ctx context.Context, request *model.ListSessionRequest) ([]model.SessionResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	sessions, err := c.SessionRepository.FindAllByUserId(c.DB.WithContext(ctx), request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find sessions : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	responses := make([]model.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response := converter.SessionToResponse(&session, request.SessionId)
+		userAgent := ParseUserAgent(session.UserAgent)
+		response.Browser = userAgent.Browser
+		response.OS = userAgent.OS
+		response.Device = userAgent.Device
+		responses[i] = *response
+	}
+	return responses, nil
+}
+
+// Delete ends one session of the user, revoking its refresh tokens
+func (c *SessionUseCase) Delete(ctx context.Context, request *model.DeleteSessionRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	session := new(entity.Session)
+	if err := c.SessionRepository.FindByIdAndUserId(tx, session, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find session : %+v", err)
+		return fiber.ErrNotFound
+	}
+
+	if err := c.End(tx, session.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// End deletes a session and revokes its refresh tokens inside tx
+func (c *SessionUseCase) End(tx *gorm.DB, sessionId string) error {
+	if err := c.SessionRepository.Delete(tx, &entity.Session{ID: sessionId}); err != nil {
+		c.Log.Warnf("Failed delete session : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.RefreshTokenRepository.RevokeFamily(tx, sessionId, time.Now().UnixMilli()); err != nil {
+		c.Log.Warnf("Failed revoke refresh tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	c.forget(sessionId)
+	return nil
+}
+
+// EndAll deletes every session of userId and revokes their refresh tokens inside tx
+func (c *SessionUseCase) EndAll(tx *gorm.DB, userId string) error {
+	sessions, err := c.SessionRepository.FindAllByUserId(tx, userId)
+	if err != nil {
+		c.Log.Warnf("Failed find sessions : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.SessionRepository.DeleteByUserId(tx, userId); err != nil {
+		c.Log.Warnf("Failed delete sessions : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := c.RefreshTokenRepository.RevokeByUserId(tx, userId, time.Now().UnixMilli()); err != nil {
+		c.Log.Warnf("Failed revoke refresh tokens : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	for _, session := range sessions {
+		c.forget(session.ID)
+	}
+	return nil
+}
+
+func (c *SessionUseCase) forget(sessionId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.touched, sessionId)
+}
+
+// sweep drops sessions not seen for a while, so idle ones do not pile up
+func (c *SessionUseCase) sweep(now int64) {
+	for sessionId, touchedAt := range c.touched {
+		if now-touchedAt >= c.TouchInterval.Milliseconds() {
+			delete(c.touched, sessionId)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"devshort-backend/internal/repository"
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// ShortCodeGenerator produces candidate short codes for a link. attempt starts
+// at zero and grows every time the previous candidate was already taken, so
+// deterministic strategies can derive a different code on retry.
+type ShortCodeGenerator interface {
+	Generate(tx *gorm.DB, longUrl string, attempt int) (string, error)
+}
+
+// RandomShortCodeGenerator picks uniformly random base62 codes
+type RandomShortCodeGenerator struct {
+	Length int
+}
+
+func NewRandomShortCodeGenerator(length int) *RandomShortCodeGenerator {
+	return &RandomShortCodeGenerator{
+		Length: length,
+	}
+}
+
+func (g *RandomShortCodeGenerator) Generate(tx *gorm.DB, longUrl string, attempt int) (string, error) {
+	code := make([]byte, 0, g.Length)
+	buffer := make([]byte, g.Length)
+	for len(code) < g.Length {
+		if _, err := rand.Read(buffer); err != nil {
+			return "", err
+		}
+		for _, b := range buffer {
+			// reject the tail of the byte range to keep every character equally likely
+			if int(b) >= 256-256%len(base62Alphabet) {
+				continue
+			}
+			code = append(code, base62Alphabet[int(b)%len(base62Alphabet)])
+			if len(code) == g.Length {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// HashShortCodeGenerator derives the code from the long url. Long urls are
+// unique, so this does not save codes over RandomShortCodeGenerator; it exists
+// for reproducible codes: a link deleted and created again, or imported into
+// another database, gets the code it had before unless that code was taken.
+type HashShortCodeGenerator struct {
+	Length int
+}
+
+func NewHashShortCodeGenerator(length int) *HashShortCodeGenerator {
+	return &HashShortCodeGenerator{
+		Length: length,
+	}
+}
+
+func (g *HashShortCodeGenerator) Generate(tx *gorm.DB, longUrl string, attempt int) (string, error) {
+	input := longUrl
+	if attempt > 0 {
+		input = longUrl + "#" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(input))
+
+	code := make([]byte, 0, g.Length)
+	for offset := 0; len(code) < g.Length && offset+8 <= len(sum); offset += 8 {
+		code = append(code, encodeBase(binary.BigEndian.Uint64(sum[offset:offset+8]), base62Alphabet)...)
+	}
+	if len(code) > g.Length {
+		code = code[:g.Length]
+	}
+	return string(code), nil
+}
+
+// MaxCounterShortCodeLength is the longest code CounterShortCodeGenerator can
+// scramble, 62^11 no longer fits the uint64 counter
+const MaxCounterShortCodeLength = 10
+
+// counterShortCodeRounds is the number of Feistel rounds, enough for the
+// round keys to mix every bit of the counter
+const counterShortCodeRounds = 8
+
+// CounterShortCodeGenerator encodes a database counter through a permutation
+// keyed by Salt, so without the salt a code tells nothing about the codes
+// issued before or after it
+type CounterShortCodeGenerator struct {
+	Sequence  *repository.LinkSequenceRepository
+	Salt      string
+	MinLength int
+}
+
+func NewCounterShortCodeGenerator(sequence *repository.LinkSequenceRepository, salt string, minLength int) *CounterShortCodeGenerator {
+	return &CounterShortCodeGenerator{
+		Sequence: sequence,
+		Salt:     salt,
+		// longer codes would not widen the space, callers reject them
+		MinLength: min(minLength, MaxCounterShortCodeLength),
+	}
+}
+
+func (g *CounterShortCodeGenerator) Generate(tx *gorm.DB, longUrl string, attempt int) (string, error) {
+	number, err := g.Sequence.Next(tx)
+	if err != nil {
+		return "", err
+	}
+	return g.Encode(uint64(number)), nil
+}
+
+// Encode maps number to a code of the shortest length from MinLength up that
+// has room for it. Within a length the numbers are permuted and padded, so
+// codes of different lengths never meet and the mapping stays one-to-one.
+// Numbers past the longest scrambled length are encoded as they are.
+func (g *CounterShortCodeGenerator) Encode(number uint64) string {
+	length := g.MinLength
+	space := pow62(length)
+	for number >= space {
+		if length == MaxCounterShortCodeLength {
+			return string(encodeBase(number, base62Alphabet))
+		}
+		length++
+		space = pow62(length)
+	}
+
+	digits := encodeBase(g.permute(number, length, space), base62Alphabet)
+	for len(digits) < length {
+		digits = append([]byte{base62Alphabet[0]}, digits...)
+	}
+	return string(digits)
+}
+
+// permute is a Feistel network over the smallest even number of bits holding
+// space, walking the cycle until it lands back inside [0, space). A bit
+// range is at most four times space, so that takes a few steps.
+func (g *CounterShortCodeGenerator) permute(number uint64, length int, space uint64) uint64 {
+	half := (bits.Len64(space-1) + 1) / 2
+	mask := uint64(1)<<half - 1
+
+	for {
+		left, right := number>>half, number&mask
+		for round := 0; round < counterShortCodeRounds; round++ {
+			left, right = right, left^(g.roundKey(length, round, right)&mask)
+		}
+		number = left<<half | right
+		if number < space {
+			return number
+		}
+	}
+}
+
+func (g *CounterShortCodeGenerator) roundKey(length int, round int, input uint64) uint64 {
+	mac := hmac.New(sha256.New, []byte(g.Salt))
+	var block [10]byte
+	block[0] = byte(length)
+	block[1] = byte(round)
+	binary.BigEndian.PutUint64(block[2:], input)
+	mac.Write(block[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+func pow62(length int) uint64 {
+	space := uint64(1)
+	for i := 0; i < length; i++ {
+		space *= uint64(len(base62Alphabet))
+	}
+	return space
+}
+
+func encodeBase(number uint64, alphabet string) []byte {
+	base := uint64(len(alphabet))
+	if number == 0 {
+		return []byte{alphabet[0]}
+	}
+
+	var digits []byte
+	for number > 0 {
+		digits = append([]byte{alphabet[number%base]}, digits...)
+		number /= base
+	}
+	return digits
+}
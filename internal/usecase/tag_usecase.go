@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/model/converter"
+	"devshort-backend/internal/repository"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var errTagExists = NewAppError(fiber.StatusConflict, "tag_exists", "Tag already exists")
+
+// TagUseCase manages the tags a user groups their links with. Links name
+// their tags, so tags are also created on the fly by Resolve.
+type TagUseCase struct {
+	DB            *gorm.DB
+	Log           *logrus.Logger
+	Validate      *validator.Validate
+	TagRepository *repository.TagRepository
+}
+
+func NewTagUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
+	tagRepository *repository.TagRepository) *TagUseCase {
+	return &TagUseCase{
+		DB:            db,
+		Log:           logger,
+		Validate:      validate,
+		TagRepository: tagRepository,
+	}
+}
+
+func (c *TagUseCase) Create(ctx context.Context, request *model.CreateTagRequest) (*model.TagResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	tag := &entity.Tag{
+		ID:     uuid.NewString(),
+		UserId: request.UserId,
+		Name:   request.Name,
+	}
+	if err := c.TagRepository.Create(tx, tag); err != nil {
+		c.Log.Warnf("Failed create tag : %+v", err)
+		return nil, duplicateTagError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.TagToResponse(tag), nil
+}
+
+func (c *TagUseCase) List(ctx context.Context, request *model.ListTagRequest) ([]model.TagResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	tags, err := c.TagRepository.FindAllByUserId(c.DB.WithContext(ctx), request.UserId)
+	if err != nil {
+		c.Log.Warnf("Failed find tags : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	responses := make([]model.TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = *converter.TagToResponse(&tag)
+	}
+	return responses, nil
+}
+
+// Update renames a tag, the links keep it
+func (c *TagUseCase) Update(ctx context.Context, request *model.UpdateTagRequest) (*model.TagResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	tag := new(entity.Tag)
+	if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find tag : %+v", err)
+		return nil, fiber.ErrNotFound
+	}
+
+	tag.Name = request.Name
+	if err := c.TagRepository.Update(tx, tag); err != nil {
+		c.Log.Warnf("Failed save tag : %+v", err)
+		return nil, duplicateTagError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.TagToResponse(tag), nil
+}
+
+// Delete removes a tag from the user and all of their links
+func (c *TagUseCase) Delete(ctx context.Context, request *model.DeleteTagRequest) error {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return NewValidationError(err)
+	}
+
+	tag := new(entity.Tag)
+	if err := c.TagRepository.FindByIdAndUserId(tx, tag, request.ID, request.UserId); err != nil {
+		c.Log.Warnf("Failed find tag : %+v", err)
+		return fiber.ErrNotFound
+	}
+
+	if err := c.TagRepository.Delete(tx, tag); err != nil {
+		c.Log.Warnf("Failed delete tag : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	return nil
+}
+
+// Resolve returns the tags of userId named names inside tx, creating the ones
+// the user does not have yet
+func (c *TagUseCase) Resolve(tx *gorm.DB, userId string, names []string) ([]entity.Tag, error) {
+	names = normalizeTagNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]entity.Tag, len(names))
+	for i, name := range names {
+		tags[i] = entity.Tag{ID: uuid.NewString(), UserId: userId, Name: name}
+	}
+	if err := c.TagRepository.CreateMissing(tx, tags); err != nil {
+		c.Log.Warnf("Failed create tags : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	found, err := c.TagRepository.FindByUserIdAndNames(tx, userId, names)
+	if err != nil {
+		c.Log.Warnf("Failed find tags : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	return found, nil
+}
+
+// normalizeTagNames trims names and drops blanks and duplicates, which differ
+// only in case as tag names are compared case insensitively
+func normalizeTagNames(names []string) []string {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+func duplicateTagError(err error) error {
+	if repository.IsDuplicateKey(err, "uk_tags_user_id_name") {
+		return errTagExists
+	}
+	return fiber.ErrInternalServerError
+}
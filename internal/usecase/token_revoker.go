@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// revocationSyncOverlap re-reads recent rows on every sync, so revocations
+// committed by other instances with a slightly skewed clock are not missed
+const revocationSyncOverlap = 5 * time.Second
+
+// TokenRevoker answers whether an access token was revoked without a query per
+// request. Revocations live in the token_revocations table and are mirrored in
+// memory; Sync pulls rows written by other instances and drops expired ones.
+type TokenRevoker struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	TokenRevocationRepository *repository.TokenRevocationRepository
+
+	mutex    sync.RWMutex
+	tokens   map[string]int64
+	sessions map[string]int64
+	users    map[string]revokedUser
+	syncedAt int64
+}
+
+type revokedUser struct {
+	issuedBefore int64
+	expiresAt    int64
+}
+
+func NewTokenRevoker(db *gorm.DB, logger *logrus.Logger,
+	tokenRevocationRepository *repository.TokenRevocationRepository) *TokenRevoker {
+	return &TokenRevoker{
+		DB:                        db,
+		Log:                       logger,
+		TokenRevocationRepository: tokenRevocationRepository,
+		tokens:                    map[string]int64{},
+		sessions:                  map[string]int64{},
+		users:                     map[string]revokedUser{},
+	}
+}
+
+// IsRevoked reports whether the token jti of userId and sessionId, issued at
+// issuedAt in unix millis, was revoked on its own, with its session or by a
+// log out everywhere
+func (r *TokenRevoker) IsRevoked(jti string, userId string, sessionId string, issuedAt int64) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if _, ok := r.tokens[jti]; ok {
+		return true
+	}
+	if _, ok := r.sessions[sessionId]; ok {
+		return true
+	}
+	if user, ok := r.users[userId]; ok && issuedAt <= user.issuedBefore {
+		return true
+	}
+	return false
+}
+
+func (r *TokenRevoker) RevokeToken(tx *gorm.DB, jti string, userId string, expiresAt int64) error {
+	revocation := &entity.TokenRevocation{
+		ID:        uuid.NewString(),
+		Jti:       &jti,
+		UserId:    userId,
+		ExpiresAt: expiresAt,
+	}
+	if err := r.TokenRevocationRepository.Create(tx, revocation); err != nil && !repository.IsDuplicateKey(err, "jti") {
+		return err
+	}
+
+	r.remember(revocation)
+	return nil
+}
+
+// RevokeSession revokes every token of sessionId. Ended sessions do not come
+// back, so the row is kept for one access token lifetime as well.
+func (r *TokenRevoker) RevokeSession(tx *gorm.DB, userId string, sessionId string, now int64, tokenTTL time.Duration) error {
+	revocation := &entity.TokenRevocation{
+		ID:        uuid.NewString(),
+		UserId:    userId,
+		SessionId: &sessionId,
+		ExpiresAt: now + tokenTTL.Milliseconds(),
+	}
+	if err := r.TokenRevocationRepository.Create(tx, revocation); err != nil {
+		return err
+	}
+
+	r.remember(revocation)
+	return nil
+}
+
+// RevokeUser revokes every token of userId issued up to now. The row is kept
+// for one access token lifetime, after which those tokens expired anyway.
+func (r *TokenRevoker) RevokeUser(tx *gorm.DB, userId string, now int64, tokenTTL time.Duration) error {
+	revocation := &entity.TokenRevocation{
+		ID:           uuid.NewString(),
+		UserId:       userId,
+		IssuedBefore: &now,
+		ExpiresAt:    now + tokenTTL.Milliseconds(),
+	}
+	if err := r.TokenRevocationRepository.Create(tx, revocation); err != nil {
+		return err
+	}
+
+	r.remember(revocation)
+	return nil
+}
+
+// Sync loads revocations written since the last sync and forgets expired ones
+func (r *TokenRevoker) Sync(ctx context.Context) error {
+	now := time.Now().UnixMilli()
+
+	r.mutex.RLock()
+	since := r.syncedAt
+	r.mutex.RUnlock()
+	if since > 0 {
+		since -= revocationSyncOverlap.Milliseconds()
+	}
+
+	db := r.DB.WithContext(ctx)
+	revocations, err := r.TokenRevocationRepository.FindActiveSince(db, since, now)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.TokenRevocationRepository.DeleteExpired(db, now); err != nil {
+		r.Log.WithError(err).Warn("failed to delete expired token revocations")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range revocations {
+		r.rememberLocked(&revocations[i])
+	}
+	for jti, expiresAt := range r.tokens {
+		if expiresAt <= now {
+			delete(r.tokens, jti)
+		}
+	}
+	for sessionId, expiresAt := range r.sessions {
+		if expiresAt <= now {
+			delete(r.sessions, sessionId)
+		}
+	}
+	for userId, user := range r.users {
+		if user.expiresAt <= now {
+			delete(r.users, userId)
+		}
+	}
+	r.syncedAt = now
+	return nil
+}
+
+// Run syncs every interval until ctx is done
+func (r *TokenRevoker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Sync(ctx); err != nil {
+				r.Log.WithError(err).Warn("failed to sync token revocations")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *TokenRevoker) remember(revocation *entity.TokenRevocation) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.rememberLocked(revocation)
+}
+
+func (r *TokenRevoker) rememberLocked(revocation *entity.TokenRevocation) {
+	if revocation.Jti != nil {
+		r.tokens[*revocation.Jti] = revocation.ExpiresAt
+		return
+	}
+	if revocation.SessionId != nil {
+		r.sessions[*revocation.SessionId] = revocation.ExpiresAt
+		return
+	}
+	if revocation.IssuedBefore != nil {
+		user := r.users[revocation.UserId]
+		if *revocation.IssuedBefore > user.issuedBefore {
+			user.issuedBefore = *revocation.IssuedBefore
+		}
+		if revocation.ExpiresAt > user.expiresAt {
+			user.expiresAt = revocation.ExpiresAt
+		}
+		r.users[revocation.UserId] = user
+	}
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"devshort-backend/internal/model"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// TokenKey is one key of the token service. Keys without a private key only
+// verify, which is how retired keys stay valid until their tokens expire.
+type TokenKey struct {
+	Kid        string
+	Algorithm  string
+	PrivateKey any
+	PublicKey  any
+}
+
+// NewTokenKey parses PEM encoded key material for algorithm. The public key is
+// derived from the private key when only that is given.
+func NewTokenKey(kid string, algorithm string, privatePem []byte, publicPem []byte) (*TokenKey, error) {
+	key := &TokenKey{Kid: kid, Algorithm: algorithm}
+
+	switch algorithm {
+	case AlgorithmRS256:
+		if len(privatePem) > 0 {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePem)
+			if err != nil {
+				return nil, err
+			}
+			key.PrivateKey = privateKey
+			key.PublicKey = &privateKey.PublicKey
+		} else {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPem)
+			if err != nil {
+				return nil, err
+			}
+			key.PublicKey = publicKey
+		}
+	case AlgorithmEdDSA:
+		if len(privatePem) > 0 {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(privatePem)
+			if err != nil {
+				return nil, err
+			}
+			key.PrivateKey = privateKey
+			key.PublicKey = privateKey.(ed25519.PrivateKey).Public()
+		} else {
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM(publicPem)
+			if err != nil {
+				return nil, err
+			}
+			key.PublicKey = publicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	return key, nil
+}
+
+func (k *TokenKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// TokenService signs access tokens with one key and verifies them with any
+// configured key, picked by the kid header
+type TokenService struct {
+	Issuer     string
+	SigningKey *TokenKey
+	Keys       map[string]*TokenKey
+}
+
+func NewTokenService(issuer string, signingKid string, keys []*TokenKey) (*TokenService, error) {
+	service := &TokenService{
+		Issuer: issuer,
+		Keys:   make(map[string]*TokenKey, len(keys)),
+	}
+	for _, key := range keys {
+		if _, ok := service.Keys[key.Kid]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.Kid)
+		}
+		service.Keys[key.Kid] = key
+	}
+
+	signingKey, ok := service.Keys[signingKid]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", signingKid)
+	}
+	if signingKey.PrivateKey == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", signingKid)
+	}
+	service.SigningKey = signingKey
+
+	return service, nil
+}
+
+func (s *TokenService) Sign(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = s.Issuer
+	token := jwt.NewWithClaims(s.SigningKey.method(), claims)
+	token.Header["kid"] = s.SigningKey.Kid
+	return token.SignedString(s.SigningKey.PrivateKey)
+}
+
+func (s *TokenService) Parse(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.Keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		// a key only verifies tokens of its own algorithm
+		if token.Method.Alg() != key.Algorithm {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithIssuer(s.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Jwks publishes the public half of every key, so other services can verify
+// tokens on their own
+func (s *TokenService) Jwks() *model.JwksResponse {
+	response := &model.JwksResponse{Keys: make([]model.JwkResponse, 0, len(s.Keys))}
+	for _, key := range s.Keys {
+		jwk := model.JwkResponse{
+			Kid: key.Kid,
+			Use: "sig",
+			Alg: key.Algorithm,
+		}
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		response.Keys = append(response.Keys, jwk)
+	}
+	slices.SortFunc(response.Keys, func(a, b model.JwkResponse) int {
+		return strings.Compare(a.Kid, b.Kid)
+	})
+	return response
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import "strings"
+
+const unknownDimension = "Unknown"
+
+type UserAgent struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+// ParseUserAgent classifies a User-Agent header into coarse browser, operating
+// system and device families. Order matters: most browsers also claim to be
+// the engines they are derived from.
+func ParseUserAgent(header string) UserAgent {
+	if header == "" {
+		return UserAgent{Browser: unknownDimension, OS: unknownDimension, Device: unknownDimension}
+	}
+
+	ua := strings.ToLower(header)
+	if containsAny(ua, "bot", "crawler", "spider", "slurp", "facebookexternalhit", "curl/", "wget/") {
+		return UserAgent{Browser: "Bot", OS: parseOS(ua), Device: "Bot"}
+	}
+
+	return UserAgent{Browser: parseBrowser(ua), OS: parseOS(ua), Device: parseDevice(ua)}
+}
+
+func parseBrowser(ua string) string {
+	switch {
+	case containsAny(ua, "edg/", "edga/", "edgios/"):
+		return "Edge"
+	case containsAny(ua, "opr/", "opera"):
+		return "Opera"
+	case strings.Contains(ua, "samsungbrowser"):
+		return "Samsung Internet"
+	case containsAny(ua, "firefox/", "fxios/"):
+		return "Firefox"
+	case containsAny(ua, "chrome/", "crios/", "chromium/"):
+		return "Chrome"
+	case strings.Contains(ua, "safari/"):
+		return "Safari"
+	case containsAny(ua, "msie ", "trident/"):
+		return "Internet Explorer"
+	default:
+		return "Other"
+	}
+}
+
+func parseOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "windows"):
+		return "Windows"
+	case strings.Contains(ua, "android"):
+		return "Android"
+	case containsAny(ua, "iphone", "ipad", "ipod"):
+		return "iOS"
+	case containsAny(ua, "mac os x", "macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "cros"):
+		return "Chrome OS"
+	case strings.Contains(ua, "linux"):
+		return "Linux"
+	default:
+		return "Other"
+	}
+}
+
+func parseDevice(ua string) string {
+	switch {
+	case containsAny(ua, "ipad", "tablet") || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return "Tablet"
+	case containsAny(ua, "mobi", "iphone", "ipod", "android"):
+		return "Mobile"
+	default:
+		return "Desktop"
+	}
+}
+
+func containsAny(value string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(value, substring) {
+			return true
+		}
+	}
+	return false
+}
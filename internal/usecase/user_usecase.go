@@ -7,61 +7,171 @@ import (
 	"devshort-backend/internal/model"
 	"devshort-backend/internal/model/converter"
 	"devshort-backend/internal/repository"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+var (
+	errUserExists          = NewAppError(fiber.StatusConflict, "user_exists", "User already exists")
+	errEmailTaken          = NewAppError(fiber.StatusConflict, "email_taken", "Email address is already in use")
+	errInvalidCredentials  = NewAppError(fiber.StatusUnauthorized, "invalid_credentials", "Wrong user id or password")
+	errInvalidRefreshToken = NewAppError(fiber.StatusUnauthorized, "invalid_refresh_token", "Refresh token is invalid or expired")
+)
+
 type UserUseCase struct {
-	DB             *gorm.DB
-	Log            *logrus.Logger
-	Validate       *validator.Validate
-	UserRepository *repository.UserRepository
-	UserProducer   *messaging.UserProducer
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validate                 *validator.Validate
+	UserRepository           *repository.UserRepository
+	RefreshTokenRepository   *repository.RefreshTokenRepository
+	TokenService             *TokenService
+	TokenRevoker             *TokenRevoker
+	SessionUseCase           *SessionUseCase
+	EmailVerificationUseCase *EmailVerificationUseCase
+	MfaUseCase               *MfaUseCase
+	PasswordPolicy           *PasswordPolicy
+	AccountLockUseCase       *AccountLockUseCase
+	LinkQuota                *LinkQuota
+	UserProducer             *messaging.UserProducer
+	AccessTokenTTL           time.Duration
+	RefreshTokenTTL          time.Duration
 }
 
 func NewUserUseCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate,
-	userRepository *repository.UserRepository, userProducer *messaging.UserProducer) *UserUseCase {
+	userRepository *repository.UserRepository, refreshTokenRepository *repository.RefreshTokenRepository,
+	tokenService *TokenService, tokenRevoker *TokenRevoker, sessionUseCase *SessionUseCase,
+	emailVerificationUseCase *EmailVerificationUseCase, mfaUseCase *MfaUseCase,
+	passwordPolicy *PasswordPolicy, accountLockUseCase *AccountLockUseCase, linkQuota *LinkQuota, userProducer *messaging.UserProducer,
+	accessTokenTTL time.Duration, refreshTokenTTL time.Duration) *UserUseCase {
 	return &UserUseCase{
-		DB:             db,
-		Log:            logger,
-		Validate:       validate,
-		UserRepository: userRepository,
-		UserProducer:   userProducer,
+		DB:                       db,
+		Log:                      logger,
+		Validate:                 validate,
+		UserRepository:           userRepository,
+		RefreshTokenRepository:   refreshTokenRepository,
+		TokenService:             tokenService,
+		TokenRevoker:             tokenRevoker,
+		SessionUseCase:           sessionUseCase,
+		EmailVerificationUseCase: emailVerificationUseCase,
+		MfaUseCase:               mfaUseCase,
+		PasswordPolicy:           passwordPolicy,
+		AccountLockUseCase:       accountLockUseCase,
+		LinkQuota:                linkQuota,
+		UserProducer:             userProducer,
+		AccessTokenTTL:           accessTokenTTL,
+		RefreshTokenTTL:          refreshTokenTTL,
 	}
 }
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// generateJWT signs an access token for the login session sessionId. iat
+// keeps millisecond precision so a log out everywhere does not catch tokens
+// issued in the same second right after it.
+func (c *UserUseCase) generateJWT(userID string, sessionId string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"id":    userID,
+		"sid":   sessionId,
+		"jti":   uuid.NewString(),
+		"scope": strings.Join(model.LoginScopes, " "),
+		"iat":   float64(now.UnixMilli()) / 1000,
+		"exp":   now.Add(c.AccessTokenTTL).Unix(),
+	}
+	return c.TokenService.Sign(claims)
+}
 
-func (c *UserUseCase) generateJWT(userID string) (string, error) {
-    claims := jwt.MapClaims{
-        "id":  userID,
-        "exp": time.Now().Add(24 * time.Hour).Unix(), // expired 1 hari
-    }
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(jwtSecret)
+// issueTokens signs an access token and stores a new refresh token in familyId
+func (c *UserUseCase) issueTokens(tx *gorm.DB, userID string, familyId string) (*model.UserResponse, error) {
+	accessToken, err := c.generateJWT(userID, familyId)
+	if err != nil {
+		c.Log.Warnf("Failed to generate jwt : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		c.Log.Warnf("Failed to generate refresh token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	record := &entity.RefreshToken{
+		ID:        uuid.NewString(),
+		UserId:    userID,
+		FamilyId:  familyId,
+		TokenHash: hashOpaqueToken(refreshToken),
+		ExpiresAt: time.Now().Add(c.RefreshTokenTTL).UnixMilli(),
+	}
+	if err := c.RefreshTokenRepository.Create(tx, record); err != nil {
+		c.Log.Warnf("Failed create refresh token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.UserResponse{
+		ID:           userID,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
 
 func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserRequest) (*model.Auth, error) {
-    token, err := jwt.Parse(request.Token, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fiber.ErrUnauthorized
-        }
-        return jwtSecret, nil
-    })
+	claims, err := c.TokenService.Parse(request.Token)
+	if err != nil {
+		c.Log.Warnf("Invalid token : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	auth, err := claimsToAuth(claims)
+	if err != nil {
+		c.Log.Warnf("Invalid token claims : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
 
-    if err != nil || !token.Valid {
-        return nil, fiber.ErrUnauthorized
-    }
+	if c.TokenRevoker.IsRevoked(auth.TokenId, auth.ID, auth.SessionId, auth.IssuedAt) {
+		c.Log.Warnf("Token %s of user %s is revoked", auth.TokenId, auth.ID)
+		return nil, fiber.ErrUnauthorized
+	}
 
-    claims := token.Claims.(jwt.MapClaims)
-    return &model.Auth{ID: claims["id"].(string)}, nil
+	return auth, nil
+}
+
+func claimsToAuth(claims jwt.MapClaims) (*model.Auth, error) {
+	id, _ := claims["id"].(string)
+	sessionId, _ := claims["sid"].(string)
+	tokenId, _ := claims["jti"].(string)
+	scope, _ := claims["scope"].(string)
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil || id == "" || tokenId == "" {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return &model.Auth{
+		ID:        id,
+		SessionId: sessionId,
+		TokenId:   tokenId,
+		IssuedAt:  issuedAt.UnixMilli(),
+		ExpiresAt: expiresAt.UnixMilli(),
+		Scopes:    strings.Fields(scope),
+	}, nil
+}
+
+// normalizeEmail lowercases email for the unique index, "" means no email
+func normalizeEmail(email string) *string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	return &email
 }
 
 func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error) {
@@ -71,7 +181,12 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 	err := c.Validate.Struct(request)
 	if err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, NewValidationError(err)
+	}
+
+	if err := c.PasswordPolicy.Check(request.Password, request.ID, request.Name); err != nil {
+		c.Log.Warnf("Password rejected by policy : %+v", err)
+		return nil, err
 	}
 
 	total, err := c.UserRepository.CountById(tx, request.ID)
@@ -82,7 +197,7 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 
 	if total > 0 {
 		c.Log.Warnf("User already exists : %+v", err)
-		return nil, fiber.ErrConflict
+		return nil, errUserExists
 	}
 
 	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
@@ -95,20 +210,44 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 		ID:       request.ID,
 		Password: string(password),
 		Name:     request.Name,
+		Email:    normalizeEmail(request.Email),
+		PlanId:   entity.DefaultPlanId,
+	}
+
+	if user.Email != nil {
+		if err := c.EmailVerificationUseCase.Claim(tx, user.ID, *user.Email); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := c.UserRepository.Create(tx, user); err != nil {
+		if repository.IsDuplicateKey(err, "uk_users_email") {
+			c.Log.Warnf("Email already in use : %+v", err)
+			return nil, errEmailTaken
+		}
 		c.Log.Warnf("Failed create user to database : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
+	var verificationToken string
+	if user.Email != nil {
+		verificationToken, err = c.EmailVerificationUseCase.Issue(tx, user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
+	if verificationToken != "" {
+		c.EmailVerificationUseCase.Mail(ctx, user, verificationToken)
+	}
+
 	if c.UserProducer != nil {
-		event := converter.UserToEvent(user)
+		event := converter.UserToEvent(user, model.UserEventCreated)
 		c.Log.Info("Publishing user created event")
 		if err = c.UserProducer.Send(event); err != nil {
 			c.Log.Warnf("Failed publish user created event : %+v", err)
@@ -122,52 +261,208 @@ func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserReq
 }
 
 func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest) (*model.UserResponse, error) {
-    tx := c.DB.WithContext(ctx).Begin()
-    defer tx.Rollback()
-
-    if err := c.Validate.Struct(request); err != nil {
-        c.Log.Warnf("Invalid request body  : %+v", err)
-        return nil, fiber.ErrBadRequest
-    }
-
-    user := new(entity.User)
-    if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-        c.Log.Warnf("Failed find user by id : %+v", err)
-        return nil, fiber.ErrUnauthorized
-    }
-
-    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
-        c.Log.Warnf("Invalid password : %+v", err)
-        return nil, fiber.ErrUnauthorized
-    }
-
-    token, err := c.generateJWT(user.ID)
-    if err != nil {
-        c.Log.Warnf("Failed to generate jwt : %+v", err)
-        return nil, fiber.ErrInternalServerError
-    }
-
-    if err := tx.Commit().Error; err != nil {
-        c.Log.Warnf("Failed commit transaction : %+v", err)
-        return nil, fiber.ErrInternalServerError
-    }
-
-    if c.UserProducer != nil {
-        event := converter.UserToEvent(user)
-        c.Log.Info("Publishing user login event")
-        if err := c.UserProducer.Send(event); err != nil {
-            c.Log.Warnf("Failed publish user login event : %+v", err)
-            return nil, fiber.ErrInternalServerError
-        }
-    } else {
-        c.Log.Info("Kafka producer is disabled, skipping user login event")
-    }
-
-    return &model.UserResponse{
-        ID:    user.ID,
-        Name:  user.Name,
-        Token: token,
-    }, nil
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body  : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	if err := c.AccountLockUseCase.Wait(ctx, request.ID, request.IpAddress); err != nil {
+		c.Log.Warnf("Login of user %s throttled : %+v", request.ID, err)
+		return nil, err
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		if err := c.AccountLockUseCase.Fail(ctx, nil, request.ID, request.IpAddress); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		c.Log.Warnf("Invalid password : %+v", err)
+		if err := c.AccountLockUseCase.Fail(ctx, user, request.ID, request.IpAddress); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+
+	// only the owner learns about the lock, anyone else can not tell the
+	// account apart from a wrong password
+	if err := c.AccountLockUseCase.Locked(user); err != nil {
+		c.Log.Warnf("User %s is locked", user.ID)
+		return nil, err
+	}
+
+	if user.TotpEnabledAt != nil {
+		mfaToken, err := c.MfaUseCase.Challenge(tx, user)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		return &model.UserResponse{
+			ID:          user.ID,
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+
+	return c.completeLogin(ctx, tx, user, request.UserAgent, request.IpAddress)
+}
+
+// LoginMfa finishes a login of a user with two factor authentication
+func (c *UserUseCase) LoginMfa(ctx context.Context, request *model.LoginMfaRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	user, ok, err := c.MfaUseCase.Redeem(tx, request.MfaToken, request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		// keep the challenge spent
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		// a wrong code counts like a wrong password, or the failures of the
+		// password step would be forgotten before the second factor is proven
+		if err := c.AccountLockUseCase.Fail(ctx, user, user.ID, request.IpAddress); err != nil {
+			return nil, err
+		}
+		return nil, NewAppError(fiber.StatusUnauthorized, "invalid_mfa_code", "Invalid two factor code")
+	}
+
+	// the account may have been locked while the challenge was open
+	if err := c.AccountLockUseCase.Locked(user); err != nil {
+		c.Log.Warnf("User %s is locked", user.ID)
+		return nil, err
+	}
+
+	return c.completeLogin(ctx, tx, user, request.UserAgent, request.IpAddress)
+}
+
+// completeLogin forgets the failed logins of the authenticated user, starts a
+// session for them and commits tx
+func (c *UserUseCase) completeLogin(ctx context.Context, tx *gorm.DB, user *entity.User, userAgent string, ip string) (*model.UserResponse, error) {
+	if err := c.AccountLockUseCase.Reset(tx, user); err != nil {
+		return nil, err
+	}
+
+	session, err := c.SessionUseCase.Start(tx, user.ID, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := c.issueTokens(tx, user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if c.UserProducer != nil {
+		event := converter.UserToEvent(user, model.UserEventLogin)
+		c.Log.Info("Publishing user login event")
+		if err := c.UserProducer.Send(event); err != nil {
+			c.Log.Warnf("Failed publish user login event : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+	} else {
+		c.Log.Info("Kafka producer is disabled, skipping user login event")
+	}
+
+	tokens.Name = user.Name
+	return tokens, nil
+}
+
+// Refresh trades a refresh token for a new access and refresh token pair. Each
+// refresh token works once; presenting one again means it leaked, so the
+// session ends with all of its tokens and its holder has to log in again.
+func (c *UserUseCase) Refresh(ctx context.Context, request *model.RefreshTokenRequest) (*model.UserResponse, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return nil, NewValidationError(err)
+	}
+
+	token := new(entity.RefreshToken)
+	if err := c.RefreshTokenRepository.FindByTokenHash(tx, token, hashOpaqueToken(request.RefreshToken)); err != nil {
+		c.Log.Warnf("Failed find refresh token : %+v", err)
+		return nil, errInvalidRefreshToken
+	}
+
+	now := time.Now().UnixMilli()
+	if token.UsedAt != nil && token.RevokedAt == nil {
+		c.Log.Warnf("Refresh token reused, ending session %s of user %s", token.FamilyId, token.UserId)
+		if err := c.SessionUseCase.End(tx, token.FamilyId); err != nil {
+			return nil, err
+		}
+		if err := c.TokenRevoker.RevokeSession(tx, token.UserId, token.FamilyId, now, c.AccessTokenTTL); err != nil {
+			c.Log.Warnf("Failed revoke session tokens : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		if err := tx.Commit().Error; err != nil {
+			c.Log.Warnf("Failed commit transaction : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		return nil, errInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil || token.RevokedAt != nil || token.ExpiresAt <= now {
+		c.Log.Warnf("Refresh token %s is no longer valid", token.ID)
+		return nil, errInvalidRefreshToken
+	}
+
+	// the session also has to be alive, refreshing counts as being seen
+	if err := c.SessionUseCase.Touch(ctx, &model.Auth{ID: token.UserId, SessionId: token.FamilyId}); err != nil {
+		return nil, err
+	}
+
+	token.UsedAt = &now
+	if err := c.RefreshTokenRepository.Update(tx, token); err != nil {
+		c.Log.Warnf("Failed save refresh token : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx, user, token.UserId); err != nil {
+		c.Log.Warnf("Failed find user by id : %+v", err)
+		return nil, fiber.ErrUnauthorized
+	}
+
+	response, err := c.issueTokens(tx, user.ID, token.FamilyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response.Name = user.Name
+	return response, nil
 }
 
 func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest) (*model.UserResponse, error) {
@@ -176,7 +471,7 @@ func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, NewValidationError(err)
 	}
 
 	user := new(entity.User)
@@ -185,19 +480,79 @@ func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest
 		return nil, fiber.ErrNotFound
 	}
 
+	quota, err := c.LinkQuota.Usage(tx, user)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.Log.Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
-	return converter.UserToResponse(user), nil
+	response := converter.UserToResponse(user)
+	response.Quota = quota
+	return response, nil
 }
 
+// Logout revokes the access token used for the request and ends its session
 func (c *UserUseCase) Logout(ctx context.Context, request *model.LogoutUserRequest) (bool, error) {
-    c.Log.Infof("User %s logged out (client-side token deletion)", request.ID)
-    return true, nil
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, NewValidationError(err)
+	}
+
+	if err := c.TokenRevoker.RevokeToken(tx, request.TokenId, request.ID, request.ExpiresAt); err != nil {
+		c.Log.Warnf("Failed revoke token : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if request.SessionId != "" {
+		if err := c.SessionUseCase.End(tx, request.SessionId); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s logged out", request.ID)
+	return true, nil
 }
 
+// LogoutAll revokes every access and refresh token of the user, on every device
+func (c *UserUseCase) LogoutAll(ctx context.Context, request *model.LogoutUserRequest) (bool, error) {
+	tx := c.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.Warnf("Invalid request body : %+v", err)
+		return false, NewValidationError(err)
+	}
+
+	now := time.Now().UnixMilli()
+	if err := c.TokenRevoker.RevokeUser(tx, request.ID, now, c.AccessTokenTTL); err != nil {
+		c.Log.Warnf("Failed revoke user tokens : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if err := c.SessionUseCase.EndAll(tx, request.ID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.Log.Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	c.Log.Infof("User %s logged out everywhere", request.ID)
+	return true, nil
+}
 
 func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserRequest) (*model.UserResponse, error) {
 	tx := c.DB.WithContext(ctx).Begin()
@@ -205,7 +560,7 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 
 	if err := c.Validate.Struct(request); err != nil {
 		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		return nil, NewValidationError(err)
 	}
 
 	user := new(entity.User)
@@ -218,7 +573,28 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		user.Name = request.Name
 	}
 
+	var verificationToken string
+	if email := normalizeEmail(request.Email); email != nil && (user.Email == nil || *user.Email != *email) {
+		// a new address has to be verified again
+		if err := c.EmailVerificationUseCase.Claim(tx, user.ID, *email); err != nil {
+			return nil, err
+		}
+		user.Email = email
+		user.VerifiedAt = nil
+
+		token, err := c.EmailVerificationUseCase.Issue(tx, user)
+		if err != nil {
+			return nil, err
+		}
+		verificationToken = token
+	}
+
 	if request.Password != "" {
+		if err := c.PasswordPolicy.Check(request.Password, user.ID, user.Name); err != nil {
+			c.Log.Warnf("Password rejected by policy : %+v", err)
+			return nil, err
+		}
+
 		password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 		if err != nil {
 			c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
@@ -228,6 +604,10 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 	}
 
 	if err := c.UserRepository.Update(tx, user); err != nil {
+		if repository.IsDuplicateKey(err, "uk_users_email") {
+			c.Log.Warnf("Email already in use : %+v", err)
+			return nil, errEmailTaken
+		}
 		c.Log.Warnf("Failed save user : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
@@ -237,8 +617,12 @@ func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserReque
 		return nil, fiber.ErrInternalServerError
 	}
 
+	if verificationToken != "" {
+		c.EmailVerificationUseCase.Mail(ctx, user, verificationToken)
+	}
+
 	if c.UserProducer != nil {
-		event := converter.UserToEvent(user)
+		event := converter.UserToEvent(user, model.UserEventUpdated)
 		c.Log.Info("Publishing user updated event")
 		if err := c.UserProducer.Send(event); err != nil {
 			c.Log.Warnf("Failed publish user updated event : %+v", err)
@@ -0,0 +1,187 @@
+package test
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createApiKey(t *testing.T, token string, requestBody model.CreateApiKeyRequest) (*http.Response, *model.WebResponse[model.ApiKeyResponse]) {
+	return authorizedJson[model.ApiKeyResponse](t, http.MethodPost, "/api/users/_current/keys", token, requestBody)
+}
+
+func apiKeyStatus(t *testing.T, method string, target string, key string) int {
+	return sendJson(t, method, target, nil, map[string]string{"X-API-Key": key}).StatusCode
+}
+
+func TestCreateApiKey(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, responseBody := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead, model.ScopeLinksWrite},
+	})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "CI pipeline", responseBody.Data.Name)
+	assert.True(t, strings.HasPrefix(responseBody.Data.Key, "dsk_"))
+	assert.True(t, strings.HasPrefix(responseBody.Data.Key, responseBody.Data.Prefix))
+	assert.Equal(t, []string{model.ScopeLinksRead, model.ScopeLinksWrite}, responseBody.Data.Scopes)
+
+	stored := new(entity.ApiKey)
+	err := db.Where("id = ?", responseBody.Data.ID).Take(stored).Error
+	assert.Nil(t, err)
+	assert.NotContains(t, stored.KeyHash, responseBody.Data.Key)
+}
+
+func TestCreateApiKeyInvalidScope(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, _ := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{"users:admin"},
+	})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestListApiKeys(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead},
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current/keys", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.ApiKeyResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Data, 1)
+	assert.Equal(t, created.Data.Prefix, responseBody.Data[0].Prefix)
+	assert.Empty(t, responseBody.Data[0].Key)
+}
+
+func TestApiKeyAuthentication(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead, model.ScopeLinksWrite},
+	})
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	assert.Equal(t, http.StatusOK, apiKeyStatus(t, http.MethodGet, "/api/links", created.Data.Key))
+
+	// the key also works as a bearer token
+	response, body := listLinks(t, created.Data.Key, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, body.Data, 1)
+
+	key := new(entity.ApiKey)
+	err := db.Where("id = ?", created.Data.ID).Take(key).Error
+	assert.Nil(t, err)
+	assert.NotNil(t, key.LastUsedAt)
+}
+
+func TestApiKeyInvalid(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	assert.Equal(t, http.StatusUnauthorized, apiKeyStatus(t, http.MethodGet, "/api/links", "dsk_not-a-key"))
+}
+
+func TestApiKeyExpired(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead},
+	})
+
+	expiresAt := time.Now().Add(-time.Minute).UnixMilli()
+	err := db.Model(new(entity.ApiKey)).Where("id = ?", created.Data.ID).Update("expires_at", expiresAt).Error
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, apiKeyStatus(t, http.MethodGet, "/api/links", created.Data.Key))
+}
+
+func TestDeleteApiKey(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead},
+	})
+
+	status := authorizedStatus(t, http.MethodDelete, "/api/users/_current/keys/"+created.Data.ID, token)
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.Equal(t, http.StatusUnauthorized, apiKeyStatus(t, http.MethodGet, "/api/links", created.Data.Key))
+}
+
+func TestApiKeyMissingScope(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "Dashboard",
+		Scopes: []string{model.ScopeLinksRead},
+	})
+
+	bodyJson, err := json.Marshal(model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("X-API-Key", created.Data.Key)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[any])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.Equal(t, "insufficient_scope", responseBody.Code)
+	assert.NotEmpty(t, responseBody.Errors)
+	assert.Contains(t, response.Header.Get("WWW-Authenticate"), `scope="links:write"`)
+
+	assert.Equal(t, http.StatusOK, apiKeyStatus(t, http.MethodGet, "/api/links", created.Data.Key))
+}
+
+func TestApiKeyCannotManageAccount(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := createApiKey(t, token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead, model.ScopeLinksWrite, model.ScopeStatsRead},
+	})
+
+	assert.Equal(t, http.StatusForbidden, apiKeyStatus(t, http.MethodGet, "/api/users/_current", created.Data.Key))
+	assert.Equal(t, http.StatusForbidden, apiKeyStatus(t, http.MethodGet, "/api/users/_current/keys", created.Data.Key))
+}
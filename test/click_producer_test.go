@@ -0,0 +1,56 @@
+package test
+
+import (
+	"devshort-backend/internal/gateway/messaging"
+	"devshort-backend/internal/model"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickProducerKeepsClicksUnderLoad(t *testing.T) {
+	// sarama's own input channel is unbuffered as well
+	saramaConfig := mocks.NewTestConfig()
+	saramaConfig.ChannelBufferSize = 0
+
+	producer := mocks.NewAsyncProducer(t, saramaConfig)
+	const clicks = 500
+	for i := 0; i < clicks; i++ {
+		producer.ExpectInputAndSucceed()
+	}
+
+	clickProducer := messaging.NewClickProducer(producer, log, clicks)
+	for i := 0; i < clicks; i++ {
+		err := clickProducer.Send(&model.ClickEvent{ID: strconv.Itoa(i), LinkId: "link"})
+		assert.Nil(t, err)
+	}
+
+	// the mock reports expectations left over, that is clicks never produced
+	assert.Nil(t, clickProducer.Close())
+}
+
+func TestClickProducerDropsWhenBufferIsFull(t *testing.T) {
+	producer := &stalledProducer{AsyncProducer: mocks.NewAsyncProducer(t, nil), input: make(chan *sarama.ProducerMessage)}
+	clickProducer := messaging.NewClickProducer(producer, log, 1)
+
+	// one click waits on the stalled input, one fills the buffer
+	assert.Nil(t, clickProducer.Send(&model.ClickEvent{ID: "1"}))
+	assert.Eventually(t, func() bool {
+		return clickProducer.Send(&model.ClickEvent{ID: "2"}) == nil
+	}, time.Second, time.Millisecond)
+	assert.ErrorIs(t, clickProducer.Send(&model.ClickEvent{ID: "3"}), messaging.ErrProducerBusy)
+}
+
+// stalledProducer never takes messages, like a producer stuck on a broker
+type stalledProducer struct {
+	*mocks.AsyncProducer
+	input chan *sarama.ProducerMessage
+}
+
+func (p *stalledProducer) Input() chan<- *sarama.ProducerMessage {
+	return p.input
+}
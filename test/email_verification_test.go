@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"devshort-backend/internal/config"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyEmail(t *testing.T, token string) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	response := postJson(t, "/api/users/_verify_email", model.VerifyEmailRequest{Token: token})
+	return response, readWebResponse[model.UserResponse](t, response)
+}
+
+// newVerifiedOnlyLinkUseCase builds a LinkUseCase with the verified email
+// switch on, which config.json leaves off
+func newVerifiedOnlyLinkUseCase() *usecase.LinkUseCase {
+	return usecase.NewLinkUseCase(db, log, validate, repository.NewLinkRepository(log), repository.NewUserRepository(log), nil,
+		nil, 1, config.NewAliasPolicy(viperConfig), nil, true,
+		usecase.NewLinkQuota(log, repository.NewLinkRepository(log), repository.NewLinkCreationRepository(log), repository.NewPlanRepository(log), clock),
+		usecase.NewTagUseCase(db, log, validate, repository.NewTagRepository(log)))
+}
+
+func TestRegisterSendsVerificationMail(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "verify", "rahasia123", "Verify", "verify@example.com")
+
+	response, responseBody := verifyEmail(t, lastMailToken(t, "verify@example.com"))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "verify@example.com", responseBody.Data.Email)
+	assert.NotZero(t, responseBody.Data.VerifiedAt)
+
+	user := GetFirstUser(t)
+	assert.NotNil(t, user.VerifiedAt)
+}
+
+func TestVerifyEmailInvalidToken(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "verify", "rahasia123", "Verify", "verify@example.com")
+	token := lastMailToken(t, "verify@example.com")
+
+	response, _ := verifyEmail(t, "wrong")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, _ = verifyEmail(t, token)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// tokens work once
+	response, _ = verifyEmail(t, token)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestChangeEmailRequiresVerification(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "verify", "rahasia123", "Verify", "verify@example.com")
+	oldToken := lastMailToken(t, "verify@example.com")
+	response, _ := verifyEmail(t, oldToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	token := login(t, "verify", "rahasia123").Token
+	bodyJson, err := json.Marshal(model.UpdateUserRequest{Email: "new@example.com"})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Nil(t, GetFirstUser(t).VerifiedAt)
+
+	response, responseBody := verifyEmail(t, lastMailToken(t, "new@example.com"))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "new@example.com", responseBody.Data.Email)
+}
+
+func TestResendVerificationMail(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "verify", "rahasia123", "Verify", "verify@example.com")
+	first := lastMailToken(t, "verify@example.com")
+	token := login(t, "verify", "rahasia123").Token
+
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodPost, "/api/users/_current/_verify_email", token))
+	second := lastMailToken(t, "verify@example.com")
+	assert.NotEqual(t, first, second)
+
+	response, _ := verifyEmail(t, first)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response, _ = verifyEmail(t, second)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	assert.Equal(t, http.StatusConflict, authorizedStatus(t, http.MethodPost, "/api/users/_current/_verify_email", token))
+}
+
+func TestCreateLinkRequiresVerifiedEmail(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "verify", "rahasia123", "Verify", "verify@example.com")
+	linkUseCase := newVerifiedOnlyLinkUseCase()
+	request := &model.CreateLinkRequest{
+		UserId:   "verify",
+		Title:    "Verified",
+		ShortUrl: "verified",
+		LongUrl:  "https://example.com",
+		IsActive: true,
+	}
+
+	_, err := linkUseCase.Create(context.Background(), request)
+	var appErr *usecase.AppError
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, fiber.StatusForbidden, appErr.Status)
+	assert.Equal(t, "email_not_verified", appErr.Code)
+
+	response, _ := verifyEmail(t, lastMailToken(t, "verify@example.com"))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	link, err := linkUseCase.Create(context.Background(), request)
+	assert.Nil(t, err)
+	assert.Equal(t, "verified", link.ShortUrl)
+}
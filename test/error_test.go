@@ -0,0 +1,99 @@
+package test
+
+import (
+	"devshort-backend/internal/config"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrorDetails(t *testing.T) {
+	ClearAll()
+
+	response, responseBody := register(t, model.RegisterUserRequest{Name: "Zhaka Hidayat", Email: "not-an-email"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "invalid_request", responseBody.Code)
+	assert.NotEmpty(t, responseBody.Errors)
+
+	require.Len(t, responseBody.Details, 3)
+	assert.Equal(t, model.FieldError{Field: "id", Code: "required", Message: "id is required"}, responseBody.Details[0])
+	assert.Equal(t, model.FieldError{Field: "password", Code: "required", Message: "password is required"}, responseBody.Details[1])
+	assert.Equal(t, "email", responseBody.Details[2].Field)
+	assert.Equal(t, "email", responseBody.Details[2].Code)
+}
+
+func TestErrorCodes(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, responseBody := register(t, model.RegisterUserRequest{ID: "zhaka", Password: "rahasia123", Name: "Zhaka Hidayat"})
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "user_exists", responseBody.Code)
+
+	response = postJson(t, "/api/users/_login", model.LoginUserRequest{ID: "zhaka", Password: "salah12345"})
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "invalid_credentials", readWebResponse[any](t, response).Code)
+
+	createLink(t, token, model.CreateLinkRequest{Title: "Taken", ShortUrl: "taken", LongUrl: "https://example.com/taken", IsActive: true})
+	response, linkBody := authorizedJson[model.LinkResponse](t, http.MethodPost, "/api/links", token,
+		model.CreateLinkRequest{Title: "Taken", ShortUrl: "taken", LongUrl: "https://example.com/other", IsActive: true})
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "short_url_taken", linkBody.Code)
+}
+
+func TestMissingLinkIsNotFound(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, responseBody := authorizedJson[any](t, http.MethodGet, "/api/links/missing", token, nil)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, "not_found", responseBody.Code)
+
+	response, responseBody = authorizedJson[any](t, http.MethodDelete, "/api/links/missing", token, nil)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, "not_found", responseBody.Code)
+}
+
+func TestInternalErrorsAreNotShown(t *testing.T) {
+	failing := fiber.New(fiber.Config{ErrorHandler: config.NewErrorHandler()})
+	failing.Get("/", func(ctx *fiber.Ctx) error {
+		return errors.New("dial tcp 10.0.0.1:3306: connection refused")
+	})
+
+	response, err := failing.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+	responseBody := readWebResponse[any](t, response)
+	assert.Equal(t, "internal_error", responseBody.Code)
+	assert.Equal(t, "Internal server error", responseBody.Errors)
+}
+
+func TestAuthenticationErrorCodes(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	get := func(header string, value string) *model.WebResponse[any] {
+		request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		response, err := app.Test(request)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		return readWebResponse[any](t, response)
+	}
+
+	assert.Equal(t, "missing_token", get("", "").Code)
+	assert.Equal(t, "invalid_token_format", get("Authorization", "Token "+token).Code)
+	assert.Equal(t, "invalid_token", get("Authorization", "Bearer forged").Code)
+	assert.Equal(t, "invalid_api_key", get("X-API-Key", usecase.ApiKeyPrefix+"forged").Code)
+
+	// the token stays valid, its session does not
+	assert.Nil(t, db.Exec("delete from sessions").Error)
+	assert.Equal(t, "session_ended", get("Authorization", "Bearer "+token).Code)
+}
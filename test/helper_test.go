@@ -1,15 +1,24 @@
 package test
 
 import (
+	"bytes"
 	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ClearAll() {
-	ClearUsers()
+	ClearClickOffsets()
 	ClearLinks()
+	ClearUsers()
+	ClearLoginFailures()
 }
 
 func ClearUsers() {
@@ -26,9 +35,79 @@ func ClearLinks() {
 	}
 }
 
+func ClearLoginFailures() {
+	err := db.Where("subject is not null").Delete(&entity.LoginFailure{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear login failure data : %+v", err)
+	}
+}
+
+func ClearClickOffsets() {
+	err := db.Where("topic is not null").Delete(&entity.ClickOffset{}).Error
+	if err != nil {
+		log.Fatalf("Failed clear click offset data : %+v", err)
+	}
+}
+
 func GetFirstUser(t *testing.T) *entity.User {
 	user := new(entity.User)
 	err := db.First(user).Error
 	assert.Nil(t, err)
 	return user
 }
+
+func GetFirstLink(t *testing.T) *entity.Link {
+	link := new(entity.Link)
+	err := db.First(link).Error
+	assert.Nil(t, err)
+	return link
+}
+
+// sendJson sends a request to the app, with body as JSON unless it is nil and
+// headers on top, and returns the response
+func sendJson(t *testing.T, method string, target string, body any, headers map[string]string) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		require.Nil(t, err)
+		reader = bytes.NewReader(bodyJson)
+	}
+
+	request := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := app.Test(request)
+	require.Nil(t, err)
+	return response
+}
+
+// readWebResponse decodes the body of response
+func readWebResponse[T any](t *testing.T, response *http.Response) *model.WebResponse[T] {
+	body, err := io.ReadAll(response.Body)
+	require.Nil(t, err)
+
+	responseBody := new(model.WebResponse[T])
+	require.Nil(t, json.Unmarshal(body, responseBody))
+	return responseBody
+}
+
+func postJson(t *testing.T, target string, body any) *http.Response {
+	return sendJson(t, http.MethodPost, target, body, nil)
+}
+
+// authorizedJson sends body on behalf of the user behind token and decodes the answer
+func authorizedJson[T any](t *testing.T, method string, target string, token string, body any) (*http.Response, *model.WebResponse[T]) {
+	response := sendJson(t, method, target, body, map[string]string{"Authorization": "Bearer " + token})
+	return response, readWebResponse[T](t, response)
+}
+
+// authorizedStatus calls an endpoint on behalf of the user behind token and returns the status
+func authorizedStatus(t *testing.T, method string, target string, token string) int {
+	return sendJson(t, method, target, nil, map[string]string{"Authorization": "Bearer " + token}).StatusCode
+}
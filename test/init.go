@@ -1,7 +1,18 @@
 package test
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"devshort-backend/internal/config"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -20,20 +31,113 @@ var log *logrus.Logger
 
 var validate *validator.Validate
 
+// mailDir collects the mails sent during the tests
+var mailDir string
+
+var clock = &testClock{}
+
+// jwtKeys are the throwaway signing keys the suite runs with
+var jwtKeys []map[string]any
+
+// testClock runs with the system clock, shifted by however much tests advanced it
+type testClock struct {
+	mutex  sync.Mutex
+	offset time.Duration
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return time.Now().Add(c.offset)
+}
+
+func (c *testClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.offset += duration
+}
+
 func init() {
 	viperConfig = config.NewViper()
+
+	dir, err := os.MkdirTemp("", "devshort-mail")
+	if err != nil {
+		panic(err)
+	}
+	mailDir = dir
+	viperConfig.Set("mail.driver", "file")
+	viperConfig.Set("mail.file.dir", mailDir)
+	viperConfig.Set("password.breached_file", "test/testdata/breached-passwords.txt")
+	jwtKeys = writeJwtKeys()
+	viperConfig.Set("jwt.keys", jwtKeys)
+	viperConfig.Set("jwt.signing_kid", jwtKeys[0]["kid"])
+	viperConfig.Set("pagination.cursor_secret", randomSecret())
+	viperConfig.Set("link.unlock.secret", randomSecret())
+	// the suite logs in far more often than a client would, rate limits have their own tests
+	viperConfig.Set("rate_limit.guest.limit", 100000)
+	viperConfig.Set("rate_limit.auth.limit", 100000)
+	viperConfig.Set("rate_limit.auth_ip.limit", 100000)
+	viperConfig.Set("rate_limit.link_create.limit", 100000)
+
 	log = config.NewLogger(viperConfig)
 	validate = config.NewValidator(viperConfig)
 	app = config.NewFiber(viperConfig)
 	db = config.NewDatabase(viperConfig, log)
 	producer := config.NewKafkaProducer(viperConfig, log)
+	asyncProducer := config.NewKafkaAsyncProducer(viperConfig, log)
 
 	config.Bootstrap(&config.BootstrapConfig{
-		DB:       db,
-		App:      app,
-		Log:      log,
-		Validate: validate,
-		Config:   viperConfig,
-		Producer: producer,
+		DB:            db,
+		App:           app,
+		Log:           log,
+		Validate:      validate,
+		Config:        viperConfig,
+		Producer:      producer,
+		AsyncProducer: asyncProducer,
+		Clock:         clock,
 	})
 }
+
+// randomSecret is a throwaway signing secret, config.json ships without secrets
+func randomSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(secret)
+}
+
+// writeJwtKeys generates an RS256 and an EdDSA key into a temporary directory,
+// config.json ships without keys
+func writeJwtKeys() []map[string]any {
+	dir, err := os.MkdirTemp("", "devshort-keys")
+	if err != nil {
+		panic(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	_, eddsaKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	return []map[string]any{
+		writeJwtKey(dir, "test-rs256", "RS256", rsaKey),
+		writeJwtKey(dir, "test-eddsa", "EdDSA", eddsaKey),
+	}
+}
+
+func writeJwtKey(dir string, kid string, algorithm string, key crypto.PrivateKey) map[string]any {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+	path := filepath.Join(dir, kid+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		panic(err)
+	}
+	return map[string]any{"kid": kid, "algorithm": algorithm, "private_key_file": path}
+}
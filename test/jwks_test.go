@@ -0,0 +1,68 @@
+package test
+
+import (
+	"devshort-backend/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwks(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.JwksResponse)
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Keys, len(jwtKeys))
+	for _, key := range responseBody.Keys {
+		assert.NotEmpty(t, key.Kid)
+		assert.Equal(t, "sig", key.Use)
+	}
+
+	// private key members must never be published
+	assert.NotContains(t, string(bytes), `"d"`)
+}
+
+func TestAccessTokenUsesSigningKey(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, viperConfig.GetString("jwt.signing_kid"), parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+}
+
+func TestAccessTokenSymmetricRejected(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	claims := jwt.MapClaims{
+		"id":    "zhaka",
+		"jti":   uuid.NewString(),
+		"scope": "account",
+		"iss":   viperConfig.GetString("jwt.issuer"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = viperConfig.GetString("jwt.signing_kid")
+	token, err := forged.SignedString([]byte(""))
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, authorizedStatus(t, http.MethodGet, "/api/users/_current", token))
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func seedClicks(t *testing.T, linkId string) {
+	clicks := []entity.LinkClick{
+		// 2026-01-01 23:30 UTC is already 2026-01-02 in Jakarta
+		{ClickedAt: time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC).UnixMilli(), Referrer: "google.com", Device: "Mobile", OS: "Android", Browser: "Chrome", Country: "ID"},
+		{ClickedAt: time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC).UnixMilli(), Referrer: "google.com", Device: "Desktop", OS: "Windows", Browser: "Chrome", Country: "ID"},
+		{ClickedAt: time.Date(2026, 1, 2, 18, 0, 0, 0, time.UTC).UnixMilli(), Referrer: "Direct", Device: "Desktop", OS: "macOS", Browser: "Safari", Country: "SG"},
+		{ClickedAt: time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC).UnixMilli(), Referrer: "twitter.com", Device: "Mobile", OS: "iOS", Browser: "Safari", Country: "US"},
+	}
+
+	for _, click := range clicks {
+		click.ID = uuid.NewString()
+		click.LinkId = linkId
+		err := db.Create(&click).Error
+		assert.Nil(t, err)
+	}
+}
+
+func getAnalytics[T any](t *testing.T, token string, path string) (*http.Response, *model.WebResponse[T]) {
+	return authorizedJson[T](t, http.MethodGet, path, token, nil)
+}
+
+func TestLinkTimeSeriesDaily(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	seedClicks(t, link.ID)
+
+	response, responseBody := getAnalytics[model.TimeSeriesResponse](t, token,
+		"/api/links/"+link.ID+"/analytics/timeseries?interval=day&tz=UTC&from=2026-01-01&to=2026-01-03")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(3), responseBody.Data.Total)
+	assert.Len(t, responseBody.Data.Points, 3)
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-01T00:00:00Z", Clicks: 1}, responseBody.Data.Points[0])
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-02T00:00:00Z", Clicks: 2}, responseBody.Data.Points[1])
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-03T00:00:00Z", Clicks: 0}, responseBody.Data.Points[2])
+}
+
+func TestLinkTimeSeriesTimezone(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	seedClicks(t, link.ID)
+
+	response, responseBody := getAnalytics[model.TimeSeriesResponse](t, token,
+		"/api/links/"+link.ID+"/analytics/timeseries?interval=day&tz=Asia/Jakarta&from=2026-01-01&to=2026-01-03")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Data.Points, 3)
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-01T00:00:00+07:00", Clicks: 0}, responseBody.Data.Points[0])
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-02T00:00:00+07:00", Clicks: 2}, responseBody.Data.Points[1])
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-03T00:00:00+07:00", Clicks: 1}, responseBody.Data.Points[2])
+}
+
+func TestLinkTimeSeriesWeekly(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	seedClicks(t, link.ID)
+
+	response, responseBody := getAnalytics[model.TimeSeriesResponse](t, token,
+		"/api/links/"+link.ID+"/analytics/timeseries?interval=week&tz=UTC&from=2026-01-01&to=2026-01-11")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(4), responseBody.Data.Total)
+	assert.Len(t, responseBody.Data.Points, 2)
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2025-12-29T00:00:00Z", Clicks: 3}, responseBody.Data.Points[0])
+	assert.Equal(t, model.TimeSeriesPointResponse{Start: "2026-01-05T00:00:00Z", Clicks: 1}, responseBody.Data.Points[1])
+}
+
+func TestLinkTimeSeriesInvalidTimezone(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	response, _ := getAnalytics[model.TimeSeriesResponse](t, token,
+		"/api/links/"+link.ID+"/analytics/timeseries?interval=day&tz=Mars/Olympus")
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestLinkBreakdown(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	seedClicks(t, link.ID)
+
+	response, responseBody := getAnalytics[model.BreakdownResponse](t, token,
+		"/api/links/"+link.ID+"/analytics/breakdown?by=browser&tz=UTC&from=2026-01-01&to=2026-01-31")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(4), responseBody.Data.Total)
+	assert.ElementsMatch(t, []model.CountStatsResponse{{Name: "Chrome", Clicks: 2}, {Name: "Safari", Clicks: 2}}, responseBody.Data.Items)
+
+	response, responseBody = getAnalytics[model.BreakdownResponse](t, token,
+		"/api/links/"+link.ID+"/analytics/breakdown?by=country&tz=UTC&from=2026-01-02&to=2026-01-02")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(2), responseBody.Data.Total)
+	assert.ElementsMatch(t, []model.CountStatsResponse{{Name: "ID", Clicks: 1}, {Name: "SG", Clicks: 1}}, responseBody.Data.Items)
+}
+
+func TestLinkBreakdownOtherUser(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	seedClicks(t, link.ID)
+
+	otherToken := registerAndLogin(t, "budi", "rahasia123", "Budi")
+
+	response, _ := getAnalytics[model.BreakdownResponse](t, otherToken,
+		"/api/links/"+link.ID+"/analytics/breakdown?by=browser")
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
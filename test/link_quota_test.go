@@ -0,0 +1,130 @@
+package test
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usePlan moves userId to a plan with the given quotas
+func usePlan(t *testing.T, userId string, maxLinks int, maxLinksPerDay int) {
+	plan := &entity.Plan{ID: "test", Name: "Test", MaxLinks: maxLinks, MaxLinksPerDay: maxLinksPerDay}
+	require.Nil(t, db.Save(plan).Error)
+	require.Nil(t, db.Model(new(entity.User)).Where("id = ?", userId).Update("plan_id", plan.ID).Error)
+}
+
+func quotaLink(i int) model.CreateLinkRequest {
+	return model.CreateLinkRequest{
+		Title:    "Quota " + strconv.Itoa(i),
+		ShortUrl: "quota" + strconv.Itoa(i),
+		LongUrl:  "https://example.com/quota/" + strconv.Itoa(i),
+		IsActive: true,
+	}
+}
+
+func TestCurrentUserQuota(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, quotaLink(1))
+
+	response, body := authorizedJson[model.UserResponse](t, http.MethodGet, "/api/users/_current", token, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	require.NotNil(t, body.Data.Quota)
+
+	quota := body.Data.Quota
+	assert.Equal(t, "free", quota.Plan)
+	assert.Equal(t, 100, quota.MaxLinks)
+	assert.Equal(t, int64(1), quota.Links)
+	assert.Equal(t, int64(99), *quota.RemainingLinks)
+	assert.Equal(t, 20, quota.MaxLinksPerDay)
+	assert.Equal(t, int64(1), quota.LinksToday)
+	assert.Equal(t, int64(19), *quota.RemainingLinksToday)
+	assert.Greater(t, quota.ResetsAt, clock.Now().UnixMilli())
+}
+
+func TestLinkQuotaExceeded(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	usePlan(t, "zhaka", 2, 0)
+
+	createLink(t, token, quotaLink(1))
+	second := createLink(t, token, quotaLink(2))
+
+	response, body := authorizedJson[model.QuotaResponse](t, http.MethodPost, "/api/links", token, quotaLink(3))
+	assert.Equal(t, http.StatusPaymentRequired, response.StatusCode)
+	assert.Equal(t, "link_quota_exceeded", body.Code)
+	assert.Equal(t, int64(2), body.Data.Links)
+	assert.Equal(t, int64(0), *body.Data.RemainingLinks)
+	assert.Nil(t, body.Data.RemainingLinksToday)
+	assert.Empty(t, response.Header.Get("Retry-After"))
+
+	// deleting a link frees its place
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodDelete, "/api/links/"+second.ID, token))
+	createLink(t, token, quotaLink(3))
+}
+
+func TestDailyLinkQuotaExceeded(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	usePlan(t, "zhaka", 0, 2)
+
+	createLink(t, token, quotaLink(1))
+	createLink(t, token, quotaLink(2))
+
+	response, body := authorizedJson[model.QuotaResponse](t, http.MethodPost, "/api/links", token, quotaLink(3))
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "daily_link_quota_exceeded", body.Code)
+	assert.Equal(t, int64(2), body.Data.LinksToday)
+	assert.NotEmpty(t, response.Header.Get("Retry-After"))
+
+	clock.Advance(24 * time.Hour)
+	createLink(t, token, quotaLink(3))
+}
+
+func TestDailyLinkQuotaCountsDeletedLinks(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	usePlan(t, "zhaka", 0, 2)
+
+	createLink(t, token, quotaLink(1))
+	second := createLink(t, token, quotaLink(2))
+
+	// deleting a link does not give its place of the day back
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodDelete, "/api/links/"+second.ID, token))
+
+	response, body := authorizedJson[model.QuotaResponse](t, http.MethodPost, "/api/links", token, quotaLink(3))
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "daily_link_quota_exceeded", body.Code)
+	assert.Equal(t, int64(1), body.Data.Links)
+	assert.Equal(t, int64(2), body.Data.LinksToday)
+}
+
+func TestLinkQuotaConcurrentCreates(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	usePlan(t, "zhaka", 3, 0)
+
+	statuses := make([]int, 8)
+	var wait sync.WaitGroup
+	for i := range statuses {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			response, _ := authorizedJson[model.LinkResponse](t, http.MethodPost, "/api/links", token, quotaLink(i))
+			statuses[i] = response.StatusCode
+		}()
+	}
+	wait.Wait()
+
+	created := 0
+	for _, status := range statuses {
+		if status == http.StatusOK {
+			created++
+		} else {
+			assert.Equal(t, http.StatusPaymentRequired, status)
+		}
+	}
+	assert.Equal(t, 3, created)
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordClick(t *testing.T, offset int64, event *model.ClickEvent) {
+	linkStatsUseCase := usecase.NewLinkStatsUseCase(db, log, validate,
+		repository.NewLinkRepository(log), repository.NewLinkStatsRepository(log), repository.NewLinkClickRepository(log))
+
+	err := linkStatsUseCase.Record(context.Background(), &model.RecordClickRequest{
+		Event:     event,
+		Topic:     "clicks",
+		Partition: 0,
+		Offset:    offset,
+	})
+	assert.Nil(t, err)
+}
+
+func TestGetLinkStats(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	now := time.Now().UnixMilli()
+	chrome := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+	firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0"
+
+	first := &model.ClickEvent{ID: "1", LinkId: link.ID, Timestamp: now, Referrer: "https://www.google.com/search", UserAgent: chrome, IpHash: "visitor-1", Country: "ID"}
+	recordClick(t, 1, first)
+	recordClick(t, 2, &model.ClickEvent{ID: "2", LinkId: link.ID, Timestamp: now, Referrer: "https://google.com/", UserAgent: chrome, IpHash: "visitor-1", Country: "ID"})
+	recordClick(t, 3, &model.ClickEvent{ID: "3", LinkId: link.ID, Timestamp: now, UserAgent: firefox, IpHash: "visitor-2", Country: "SG"})
+	// redelivery of the first message must not be counted again
+	recordClick(t, 1, first)
+	// nor is the first click published again under a new offset, which is marked as processed
+	recordClick(t, 4, first)
+	var offsets int64
+	assert.Nil(t, db.Model(new(entity.ClickOffset)).Where("offset_id = ?", 4).Count(&offsets).Error)
+	assert.Equal(t, int64(1), offsets)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/links/"+link.ID+"/stats", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.LinkStatsResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(3), responseBody.Data.TotalClicks)
+	assert.Equal(t, int64(2), responseBody.Data.UniqueVisitors)
+	assert.Len(t, responseBody.Data.Daily, 1)
+	assert.Equal(t, int64(3), responseBody.Data.Daily[0].Clicks)
+	assert.Equal(t, int64(2), responseBody.Data.Daily[0].UniqueVisitors)
+	assert.Equal(t, model.CountStatsResponse{Name: "google.com", Clicks: 2}, responseBody.Data.TopReferrers[0])
+	assert.Equal(t, model.CountStatsResponse{Name: "Chrome", Clicks: 2}, responseBody.Data.Browsers[0])
+	assert.Equal(t, model.CountStatsResponse{Name: "ID", Clicks: 2}, responseBody.Data.Countries[0])
+}
+
+func TestGetLinkStatsOtherUser(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	recordClick(t, 1, &model.ClickEvent{ID: "1", LinkId: link.ID, Timestamp: time.Now().UnixMilli(), IpHash: "visitor-1"})
+
+	otherToken := registerAndLogin(t, "budi", "rahasia123", "Budi")
+
+	assert.Equal(t, http.StatusNotFound, authorizedStatus(t, http.MethodGet, "/api/links/"+link.ID+"/stats", otherToken))
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodGet, "/api/links/"+link.ID+"/stats", token))
+}
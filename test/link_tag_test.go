@@ -0,0 +1,174 @@
+package test
+
+import (
+	"devshort-backend/internal/model"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func taggedLink(shortUrl string, tags ...string) model.CreateLinkRequest {
+	return model.CreateLinkRequest{
+		Title:    "Tagged " + shortUrl,
+		ShortUrl: shortUrl,
+		LongUrl:  "https://example.com/" + shortUrl,
+		IsActive: true,
+		Tags:     tags,
+	}
+}
+
+func shortUrls(links []model.LinkResponse) []string {
+	result := make([]string, len(links))
+	for i, link := range links {
+		result[i] = link.ShortUrl
+	}
+	return result
+}
+
+func TestCreateLinkWithTags(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	link := createLink(t, token, taggedLink("tagged", "work", " docs ", "Work", ""))
+	assert.Equal(t, []string{"docs", "work"}, link.Tags)
+
+	response, body := authorizedJson[model.LinkResponse](t, http.MethodGet, "/api/links/"+link.ID, token, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"docs", "work"}, body.Data.Tags)
+
+	// tags used by links show up in the tag list
+	response, tags := authorizedJson[[]model.TagResponse](t, http.MethodGet, "/api/tags", token, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	require.Len(t, tags.Data, 2)
+	assert.Equal(t, "docs", tags.Data[0].Name)
+	assert.Equal(t, "work", tags.Data[1].Name)
+
+	// untagged links have an empty list
+	untagged := createLink(t, token, taggedLink("untagged"))
+	assert.NotNil(t, untagged.Tags)
+	assert.Empty(t, untagged.Tags)
+}
+
+func TestCreateLinkWithInvalidTag(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, body := authorizedJson[any](t, http.MethodPost, "/api/links", token, taggedLink("tagged", "a,b"))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "invalid_request", body.Code)
+	require.NotEmpty(t, body.Details)
+	assert.Equal(t, "tags[0]", body.Details[0].Field)
+}
+
+func TestUpdateLinkTags(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, taggedLink("tagged", "work", "docs"))
+
+	update := map[string]any{
+		"title":     link.Title,
+		"short_url": link.ShortUrl,
+		"long_url":  link.LongUrl,
+		"is_active": true,
+	}
+
+	// omitting tags keeps them
+	response, body := authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"docs", "work"}, body.Data.Tags)
+
+	update["tags"] = []string{"personal", "work"}
+	response, body = authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"personal", "work"}, body.Data.Tags)
+
+	// an empty list removes every tag, the tags themselves stay
+	update["tags"] = []string{}
+	response, body = authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, body.Data.Tags)
+
+	_, tags := authorizedJson[[]model.TagResponse](t, http.MethodGet, "/api/tags", token, nil)
+	assert.Len(t, tags.Data, 3)
+}
+
+func TestTagCrud(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, created := authorizedJson[model.TagResponse](t, http.MethodPost, "/api/tags", token, model.CreateTagRequest{Name: "work"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "work", created.Data.Name)
+	assert.NotEmpty(t, created.Data.ID)
+
+	response, body := authorizedJson[any](t, http.MethodPost, "/api/tags", token, model.CreateTagRequest{Name: "work"})
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "tag_exists", body.Code)
+
+	link := createLink(t, token, taggedLink("tagged", "work"))
+
+	// renaming a tag renames it on its links
+	response, updated := authorizedJson[model.TagResponse](t, http.MethodPatch, "/api/tags/"+created.Data.ID, token, model.UpdateTagRequest{Name: "office"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "office", updated.Data.Name)
+
+	_, found := authorizedJson[model.LinkResponse](t, http.MethodGet, "/api/links/"+link.ID, token, nil)
+	assert.Equal(t, []string{"office"}, found.Data.Tags)
+
+	// deleting a tag takes it off its links
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodDelete, "/api/tags/"+created.Data.ID, token))
+	assert.Equal(t, http.StatusNotFound, authorizedStatus(t, http.MethodDelete, "/api/tags/"+created.Data.ID, token))
+
+	_, found = authorizedJson[model.LinkResponse](t, http.MethodGet, "/api/links/"+link.ID, token, nil)
+	assert.Empty(t, found.Data.Tags)
+}
+
+func TestTagsAreScopedToUser(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	_, created := authorizedJson[model.TagResponse](t, http.MethodPost, "/api/tags", token, model.CreateTagRequest{Name: "work"})
+
+	other := registerAndLogin(t, "khannedy", "rahasia123", "Eko Khannedy")
+	assert.Equal(t, http.StatusNotFound, authorizedStatus(t, http.MethodDelete, "/api/tags/"+created.Data.ID, other))
+
+	// the same name is free for another user
+	response, _ := authorizedJson[model.TagResponse](t, http.MethodPost, "/api/tags", other, model.CreateTagRequest{Name: "work"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	_, tags := authorizedJson[[]model.TagResponse](t, http.MethodGet, "/api/tags", other, nil)
+	assert.Len(t, tags.Data, 1)
+}
+
+func TestListLinksByTags(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, taggedLink("both", "work", "docs"))
+	createLink(t, token, taggedLink("work", "work"))
+	createLink(t, token, taggedLink("docs", "docs"))
+	createLink(t, token, taggedLink("none"))
+
+	response, body := listLinks(t, token, "?tag=work&sort=short_url&order=asc")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"both", "work"}, shortUrls(body.Data))
+	assert.Equal(t, int64(2), body.Paging.TotalItem)
+
+	// any is the default and matches links with one of the tags
+	_, body = listLinks(t, token, "?tag=work&tag=docs&sort=short_url&order=asc")
+	assert.Equal(t, []string{"both", "docs", "work"}, shortUrls(body.Data))
+
+	_, body = listLinks(t, token, "?tag=work,docs&tag_mode=all")
+	assert.Equal(t, []string{"both"}, shortUrls(body.Data))
+	assert.Equal(t, []string{"docs", "work"}, body.Data[0].Tags)
+
+	// names match whatever their case, so a repeated name does not ask for a second tag
+	_, body = listLinks(t, token, "?tag=WORK&sort=short_url&order=asc")
+	assert.Equal(t, []string{"both", "work"}, shortUrls(body.Data))
+
+	_, body = listLinks(t, token, "?tag=Work&tag=work,DOCS&tag_mode=all")
+	assert.Equal(t, []string{"both"}, shortUrls(body.Data))
+
+	_, body = listLinks(t, token, "?tag=missing")
+	assert.Empty(t, body.Data)
+
+	_, body = listLinks(t, token, "?tag=work&tag=docs&tag_mode=all&mode=cursor")
+	assert.Equal(t, []string{"both"}, shortUrls(body.Data))
+
+	response, _ = listLinks(t, token, "?tag=work&tag_mode=some")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
@@ -0,0 +1,712 @@
+package test
+
+import (
+	"context"
+	"devshort-backend/internal/config"
+	delivery "devshort-backend/internal/delivery/http"
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// Helper function to create a link owned by the user behind token
+func createLink(t *testing.T, token string, requestBody model.CreateLinkRequest) model.LinkResponse {
+	response, responseBody := authorizedJson[model.LinkResponse](t, http.MethodPost, "/api/links", token, requestBody)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return responseBody.Data
+}
+
+func TestCreateLinkDefaultRedirectType(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	assert.Equal(t, "devshort", link.ShortUrl)
+	assert.Equal(t, http.StatusFound, link.RedirectType)
+}
+
+func TestRedirect(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/devshort", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	assert.Equal(t, "https://example.com/devshort", response.Header.Get("Location"))
+}
+
+func TestRedirectPermanent(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, model.CreateLinkRequest{
+		Title:        "Devshort",
+		ShortUrl:     "devshort",
+		LongUrl:      "https://example.com/devshort",
+		RedirectType: http.StatusMovedPermanently,
+		IsActive:     true,
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/devshort", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusMovedPermanently, response.StatusCode)
+	assert.Equal(t, "https://example.com/devshort", response.Header.Get("Location"))
+}
+
+func TestCreateLinkInvalidRedirectType(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	requestBody := model.CreateLinkRequest{
+		Title:        "Devshort",
+		ShortUrl:     "devshort",
+		LongUrl:      "https://example.com/devshort",
+		RedirectType: http.StatusOK,
+		IsActive:     true,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, http.StatusOK, response.StatusCode)
+}
+
+func TestRedirectNotFound(t *testing.T) {
+	ClearAll()
+
+	request := httptest.NewRequest(http.MethodGet, "/notfound", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[any])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Errors)
+}
+
+func TestRedirectInactive(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	link := GetFirstLink(t)
+	link.IsActive = false
+	err := db.Save(link).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/devshort", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusGone, response.StatusCode)
+	assert.Empty(t, response.Header.Get("Location"))
+}
+
+func TestCreateLinkGeneratedShortUrl(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	assert.Len(t, link.ShortUrl, viperConfig.GetInt("shortcode.length"))
+
+	request := httptest.NewRequest(http.MethodGet, "/"+link.ShortUrl, nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	assert.Equal(t, "https://example.com/devshort", response.Header.Get("Location"))
+}
+
+func TestCreateLinkDuplicateShortUrl(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	requestBody := model.CreateLinkRequest{
+		Title:    "Another",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/another",
+		IsActive: true,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.LinkResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Errors)
+}
+
+func TestCounterShortCodeIsUnique(t *testing.T) {
+	generator := usecase.NewCounterShortCodeGenerator(nil, "devshort", 7)
+
+	codes := make(map[string]uint64)
+	for number := uint64(1); number <= 10000; number++ {
+		code := generator.Encode(number)
+		assert.GreaterOrEqual(t, len(code), 7)
+
+		previous, exists := codes[code]
+		assert.False(t, exists, "code %s generated for %d and %d", code, previous, number)
+		codes[code] = number
+	}
+}
+
+func TestCounterShortCodeGrowsWhenRangeIsUsedUp(t *testing.T) {
+	generator := usecase.NewCounterShortCodeGenerator(nil, "devshort", 2)
+
+	codes := make(map[string]uint64)
+	for number := uint64(0); number < 62*62+100; number++ {
+		code := generator.Encode(number)
+		if number < 62*62 {
+			assert.Len(t, code, 2)
+		} else {
+			assert.Len(t, code, 3)
+		}
+
+		previous, exists := codes[code]
+		assert.False(t, exists, "code %s generated for %d and %d", code, previous, number)
+		codes[code] = number
+	}
+}
+
+func TestCounterShortCodeDependsOnSalt(t *testing.T) {
+	generator := usecase.NewCounterShortCodeGenerator(nil, "devshort", 7)
+	other := usecase.NewCounterShortCodeGenerator(nil, "another-salt", 7)
+
+	same := 0
+	for number := uint64(1); number <= 100; number++ {
+		if generator.Encode(number) == other.Encode(number) {
+			same++
+		}
+	}
+	assert.Zero(t, same)
+}
+
+func TestCreateLinkInvalidAlias(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	aliases := []string{
+		"api",
+		"API",
+		"ab",
+		"with/slash",
+		"emoji😀",
+		strings.Repeat("a", 51),
+	}
+
+	for _, alias := range aliases {
+		requestBody := model.CreateLinkRequest{
+			Title:    "Devshort",
+			ShortUrl: alias,
+			LongUrl:  "https://example.com/devshort",
+			IsActive: true,
+		}
+
+		bodyJson, err := json.Marshal(requestBody)
+		assert.Nil(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(string(bodyJson)))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+
+		bytes, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+
+		responseBody := new(model.WebResponse[model.LinkResponse])
+		err = json.Unmarshal(bytes, responseBody)
+		assert.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, alias)
+		assert.NotEmpty(t, responseBody.Errors, alias)
+	}
+}
+
+func TestCreateLinkAliasIsCaseInsensitive(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "DevShort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+	assert.Equal(t, "devshort", link.ShortUrl)
+
+	request := httptest.NewRequest(http.MethodGet, "/DEVSHORT", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	assert.Equal(t, "https://example.com/devshort", response.Header.Get("Location"))
+
+	requestBody := model.CreateLinkRequest{
+		Title:    "Another",
+		ShortUrl: "devSHORT",
+		LongUrl:  "https://example.com/another",
+		IsActive: true,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err = app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func listLinks(t *testing.T, token string, query string) (*http.Response, *model.WebResponse[[]model.LinkResponse]) {
+	return authorizedJson[[]model.LinkResponse](t, http.MethodGet, "/api/links"+query, token, nil)
+}
+
+func createLinks(t *testing.T, token string, total int) {
+	for i := 0; i < total; i++ {
+		createLink(t, token, model.CreateLinkRequest{
+			Title:    "Link " + strconv.Itoa(i),
+			ShortUrl: "link" + strconv.Itoa(i),
+			LongUrl:  "https://example.com/" + strconv.Itoa(i),
+			IsActive: true,
+		})
+	}
+}
+
+func TestListLinksPaging(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLinks(t, token, 15)
+
+	response, responseBody := listLinks(t, token, "?page=2&size=10")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Data, 5)
+	assert.Equal(t, 2, responseBody.Paging.Page)
+	assert.Equal(t, 10, responseBody.Paging.Size)
+	assert.Equal(t, int64(15), responseBody.Paging.TotalItem)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalPage)
+}
+
+func TestListLinksSearchAndSort(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLinks(t, token, 12)
+
+	response, responseBody := listLinks(t, token, "?search=Link%201&sort=title&order=asc")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(3), responseBody.Paging.TotalItem)
+	assert.Equal(t, "Link 1", responseBody.Data[0].Title)
+	assert.Equal(t, "Link 10", responseBody.Data[1].Title)
+	assert.Equal(t, "Link 11", responseBody.Data[2].Title)
+}
+
+func TestListLinksFilterActive(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLinks(t, token, 3)
+
+	link := GetFirstLink(t)
+	link.IsActive = false
+	err := db.Save(link).Error
+	assert.Nil(t, err)
+
+	response, responseBody := listLinks(t, token, "?is_active=false")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(1), responseBody.Paging.TotalItem)
+	assert.Equal(t, link.ID, responseBody.Data[0].ID)
+
+	response, responseBody = listLinks(t, token, "?is_active=true")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(2), responseBody.Paging.TotalItem)
+}
+
+func TestListLinksInvalidSort(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, _ := listLinks(t, token, "?sort=password")
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestListLinksCursor(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLinks(t, token, 5)
+
+	response, firstPage := listLinks(t, token, "?mode=cursor&size=2")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, firstPage.Data, 2)
+	assert.Nil(t, firstPage.Paging)
+	assert.NotEmpty(t, firstPage.Cursor.NextCursor)
+	assert.Empty(t, firstPage.Cursor.PrevCursor)
+
+	response, secondPage := listLinks(t, token, "?size=2&cursor="+firstPage.Cursor.NextCursor)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, secondPage.Data, 2)
+	assert.NotEmpty(t, secondPage.Cursor.NextCursor)
+	assert.NotEmpty(t, secondPage.Cursor.PrevCursor)
+
+	response, thirdPage := listLinks(t, token, "?size=2&cursor="+secondPage.Cursor.NextCursor)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, thirdPage.Data, 1)
+	assert.Empty(t, thirdPage.Cursor.NextCursor)
+	assert.NotEmpty(t, thirdPage.Cursor.PrevCursor)
+
+	seen := make(map[string]bool)
+	for _, page := range [][]model.LinkResponse{firstPage.Data, secondPage.Data, thirdPage.Data} {
+		for _, link := range page {
+			assert.False(t, seen[link.ID])
+			seen[link.ID] = true
+		}
+	}
+	assert.Len(t, seen, 5)
+
+	// a link created meanwhile must not shift the pages behind the cursor
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Newest",
+		ShortUrl: "newest",
+		LongUrl:  "https://example.com/newest",
+		IsActive: true,
+	})
+
+	response, previousPage := listLinks(t, token, "?size=2&cursor="+thirdPage.Cursor.PrevCursor)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, secondPage.Data, previousPage.Data)
+}
+
+func TestListLinksTamperedCursor(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLinks(t, token, 3)
+
+	_, firstPage := listLinks(t, token, "?mode=cursor&size=1")
+	payload, _, _ := strings.Cut(firstPage.Cursor.NextCursor, ".")
+
+	response, _ := listLinks(t, token, "?size=1&cursor="+payload+".forged")
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestCreateLinkExpiresInPast(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	expiresAt := time.Now().Add(-time.Hour).UnixMilli()
+	bodyJson, err := json.Marshal(model.CreateLinkRequest{
+		Title:     "Devshort",
+		ShortUrl:  "devshort",
+		LongUrl:   "https://example.com/devshort",
+		IsActive:  true,
+		ExpiresAt: &expiresAt,
+	})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestRedirectExpired(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	expiresAt := time.Now().Add(-time.Minute).UnixMilli()
+	link := GetFirstLink(t)
+	link.ExpiresAt = &expiresAt
+	err := db.Save(link).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/devshort", nil)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusGone, response.StatusCode)
+	assert.Empty(t, response.Header.Get("Location"))
+}
+
+func TestRedirectMaxClicks(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	maxClicks := int64(1)
+	createLink(t, token, model.CreateLinkRequest{
+		Title:     "Devshort",
+		ShortUrl:  "devshort",
+		LongUrl:   "https://example.com/devshort",
+		IsActive:  true,
+		MaxClicks: &maxClicks,
+	})
+
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/devshort", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+
+	response, err = app.Test(httptest.NewRequest(http.MethodGet, "/devshort", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusGone, response.StatusCode)
+
+	assert.Equal(t, int64(1), GetFirstLink(t).ClickCount)
+}
+
+func TestUpdateLinkKeepsConcurrentClicks(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	maxClicks := int64(5)
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:     "Devshort",
+		ShortUrl:  "devshort",
+		LongUrl:   "https://example.com/devshort",
+		IsActive:  true,
+		MaxClicks: &maxClicks,
+	})
+
+	// a redirect counts a click after the update read the link, before it writes
+	name := "test:concurrent_click"
+	err := db.Callback().Update().Before("gorm:update").Register(name, func(tx *gorm.DB) {
+		if tx.Statement.Table == "links" {
+			assert.Nil(t, db.Exec("UPDATE links SET click_count = click_count + 1 WHERE id = ?", link.ID).Error)
+		}
+	})
+	assert.Nil(t, err)
+	defer db.Callback().Update().Remove(name)
+
+	update := map[string]any{
+		"title":      "Renamed",
+		"short_url":  link.ShortUrl,
+		"long_url":   link.LongUrl,
+		"is_active":  true,
+		"max_clicks": maxClicks,
+	}
+	response, _ := authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	saved := GetFirstLink(t)
+	assert.Equal(t, "Renamed", saved.Title)
+	assert.Equal(t, int64(1), saved.ClickCount)
+}
+
+func TestDeactivateExpiredLinks(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Devshort",
+		ShortUrl: "devshort",
+		LongUrl:  "https://example.com/devshort",
+		IsActive: true,
+	})
+
+	expiresAt := time.Now().Add(-time.Minute).UnixMilli()
+	link := GetFirstLink(t)
+	link.ExpiresAt = &expiresAt
+	err := db.Save(link).Error
+	assert.Nil(t, err)
+
+	linkExpiryUseCase := usecase.NewLinkExpiryUseCase(db, log, repository.NewLinkRepository(log), nil)
+	total, err := linkExpiryUseCase.DeactivateExpired(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, total)
+	assert.False(t, GetFirstLink(t).IsActive)
+}
+
+func TestRedirectExpiredFallbackAfterDeactivation(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	expiresAt := time.Now().Add(time.Minute).UnixMilli()
+	maxClicks := int64(1)
+	createLink(t, token, model.CreateLinkRequest{
+		Title:     "Expired",
+		ShortUrl:  "expired",
+		LongUrl:   "https://example.com/expired",
+		IsActive:  true,
+		ExpiresAt: &expiresAt,
+	})
+	createLink(t, token, model.CreateLinkRequest{
+		Title:     "Used up",
+		ShortUrl:  "usedup",
+		LongUrl:   "https://example.com/usedup",
+		IsActive:  true,
+		MaxClicks: &maxClicks,
+	})
+	createLink(t, token, model.CreateLinkRequest{
+		Title:    "Inactive",
+		ShortUrl: "inactive",
+		LongUrl:  "https://example.com/inactive",
+		IsActive: false,
+	})
+
+	pastExpiry := time.Now().Add(-time.Minute).UnixMilli()
+	assert.Nil(t, db.Model(new(entity.Link)).Where("short_url = ?", "expired").Update("expires_at", pastExpiry).Error)
+	assert.Nil(t, db.Model(new(entity.Link)).Where("short_url = ?", "usedup").Update("click_count", maxClicks).Error)
+
+	linkExpiryUseCase := usecase.NewLinkExpiryUseCase(db, log, repository.NewLinkRepository(log), nil)
+	total, err := linkExpiryUseCase.DeactivateExpired(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+
+	// config.json has no fallback, so the redirect gets its own app with one
+	fallbackUrl := "https://example.com/expired-fallback"
+	redirectUseCase := usecase.NewRedirectUseCase(db, log, validate, repository.NewLinkRepository(log), config.NewAliasPolicy(viperConfig),
+		nil, viperConfig.GetString("click.ip_salt"), config.NewLinkUnlocker(viperConfig, log))
+	redirectController := delivery.NewRedirectController(redirectUseCase, log, "", fallbackUrl)
+	fallbackApp := config.NewFiber(viperConfig)
+	fallbackApp.Get("/:code", redirectController.Redirect)
+
+	for _, shortUrl := range []string{"expired", "usedup"} {
+		response, err := fallbackApp.Test(httptest.NewRequest(http.MethodGet, "/"+shortUrl, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusFound, response.StatusCode, shortUrl)
+		assert.Equal(t, fallbackUrl, response.Header.Get("Location"), shortUrl)
+	}
+
+	// links switched off by their owner do not fall back
+	response, err := fallbackApp.Test(httptest.NewRequest(http.MethodGet, "/inactive", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusGone, response.StatusCode)
+}
+
+func TestUpdateLinkKeepsGeneratedShortUrl(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Generated",
+		LongUrl:  "https://example.com/generated",
+		IsActive: true,
+	})
+	other := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Other",
+		LongUrl:  "https://example.com/other",
+		IsActive: true,
+	})
+
+	// generated codes are mixed case, unlike the aliases users pick
+	assert.Nil(t, db.Model(new(entity.Link)).Where("id = ?", link.ID).Update("short_url", "aB3xY9z").Error)
+	assert.Nil(t, db.Model(new(entity.Link)).Where("id = ?", other.ID).Update("short_url", "ab3xy9z").Error)
+
+	update := map[string]any{
+		"title":     "Renamed",
+		"short_url": "aB3xY9z",
+		"long_url":  link.LongUrl,
+		"is_active": true,
+	}
+	response, body := authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "aB3xY9z", body.Data.ShortUrl)
+	assert.Equal(t, "Renamed", body.Data.Title)
+
+	// a new short url is held to the alias policy
+	update["short_url"] = "API"
+	response, body = authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "short_url", body.Details[0].Field)
+	assert.Equal(t, "alias", body.Details[0].Code)
+
+	update["short_url"] = "MyAlias"
+	response, body = authorizedJson[model.LinkResponse](t, http.MethodPatch, "/api/links/"+link.ID, token, update)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "myalias", body.Data.ShortUrl)
+}
+
+// fixedShortCodes hands out codes in order, one per attempt
+type fixedShortCodes []string
+
+func (g fixedShortCodes) Generate(tx *gorm.DB, longUrl string, attempt int) (string, error) {
+	return g[attempt], nil
+}
+
+func TestGeneratedShortUrlSkipsReservedWords(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	linkRepository := repository.NewLinkRepository(log)
+	linkUseCase := usecase.NewLinkUseCase(db, log, validate, linkRepository, repository.NewUserRepository(log), nil,
+		fixedShortCodes{"healthz", "Abc1234"}, 2, config.NewAliasPolicy(viperConfig), config.NewCursorCodec(viperConfig, log), false,
+		usecase.NewLinkQuota(log, linkRepository, repository.NewLinkCreationRepository(log), repository.NewPlanRepository(log), clock),
+		usecase.NewTagUseCase(db, log, validate, repository.NewTagRepository(log)))
+
+	link, err := linkUseCase.Create(context.Background(), &model.CreateLinkRequest{
+		UserId:   "zhaka",
+		Title:    "Generated",
+		LongUrl:  "https://example.com/generated",
+		IsActive: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Abc1234", link.ShortUrl)
+}
@@ -0,0 +1,207 @@
+package test
+
+import (
+	"context"
+	"devshort-backend/internal/config"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/repository"
+	"devshort-backend/internal/usecase"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createProtectedLink(t *testing.T) model.LinkResponse {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	return createLink(t, token, model.CreateLinkRequest{
+		Title:    "Internal Docs",
+		ShortUrl: "docs",
+		LongUrl:  "https://example.com/internal/docs",
+		IsActive: true,
+		Password: "open-sesame",
+	})
+}
+
+func unlockLink(t *testing.T, code string, password string) (*http.Response, *model.WebResponse[model.UnlockLinkResponse]) {
+	response := postJson(t, "/"+code+"/unlock", map[string]string{"password": password})
+	return response, readWebResponse[model.UnlockLinkResponse](t, response)
+}
+
+func TestCreateLinkWithPassword(t *testing.T) {
+	link := createProtectedLink(t)
+	assert.True(t, link.HasPassword)
+
+	stored := GetFirstLink(t)
+	assert.NotNil(t, stored.Password)
+	assert.NotEqual(t, "open-sesame", *stored.Password)
+}
+
+func TestRedirectLocked(t *testing.T) {
+	createProtectedLink(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Empty(t, response.Header.Get("Location"))
+}
+
+func TestRedirectLockedHtml(t *testing.T) {
+	createProtectedLink(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	request.Header.Set("Accept", "text/html")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Content-Type"), "text/html")
+	assert.Contains(t, string(bytes), `action="/docs/unlock"`)
+}
+
+func TestUnlockLink(t *testing.T) {
+	createProtectedLink(t)
+
+	response, responseBody := unlockLink(t, "docs", "open-sesame")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "https://example.com/internal/docs", responseBody.Data.LongUrl)
+	assert.NotEmpty(t, responseBody.Data.Token)
+
+	cookies := response.Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "/docs", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	request.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: cookies[0].Value})
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	assert.Equal(t, "https://example.com/internal/docs", response.Header.Get("Location"))
+}
+
+func TestUnlockLinkForm(t *testing.T) {
+	createProtectedLink(t)
+
+	form := url.Values{"password": {"open-sesame"}}
+	request := httptest.NewRequest(http.MethodPost, "/docs/unlock", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+	assert.Equal(t, "https://example.com/internal/docs", response.Header.Get("Location"))
+	assert.Len(t, response.Cookies(), 1)
+}
+
+func TestUnlockLinkWrongPassword(t *testing.T) {
+	createProtectedLink(t)
+
+	response, responseBody := unlockLink(t, "docs", "guess")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Errors)
+	assert.Empty(t, response.Cookies())
+}
+
+func TestUnlockLinkTamperedToken(t *testing.T) {
+	createProtectedLink(t)
+
+	_, responseBody := unlockLink(t, "docs", "open-sesame")
+	expiresAt, _, _ := strings.Cut(responseBody.Data.Token, ".")
+
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	request.Header.Set("Accept", "application/json")
+	request.AddCookie(&http.Cookie{Name: "devshort_unlock", Value: expiresAt + "9.forged"})
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestUnlockLinkThrottled(t *testing.T) {
+	createProtectedLink(t)
+
+	maxAttempts := viperConfig.GetInt("link.unlock.max_attempts")
+	for i := 0; i < maxAttempts; i++ {
+		response, _ := unlockLink(t, "docs", "guess")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	}
+
+	response, _ := unlockLink(t, "docs", "open-sesame")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+}
+
+func TestUnlockLinkThrottledPerClient(t *testing.T) {
+	createProtectedLink(t)
+	redirectUseCase := usecase.NewRedirectUseCase(db, log, validate, repository.NewLinkRepository(log), config.NewAliasPolicy(viperConfig),
+		nil, viperConfig.GetString("click.ip_salt"), config.NewLinkUnlocker(viperConfig, log))
+
+	unlock := func(ip string, password string) error {
+		_, err := redirectUseCase.Unlock(context.Background(), &model.UnlockLinkRequest{
+			ResolveLinkRequest: model.ResolveLinkRequest{ShortUrl: "docs", IpAddress: ip},
+			Password:           password,
+		})
+		return err
+	}
+
+	maxAttempts := viperConfig.GetInt("link.unlock.max_attempts")
+	for i := 0; i < maxAttempts; i++ {
+		assert.Equal(t, "wrong_password", usecase.ToAppError(unlock("203.0.113.1", "guess")).Code)
+	}
+	assert.Equal(t, "too_many_password_attempts", usecase.ToAppError(unlock("203.0.113.1", "open-sesame")).Code)
+
+	// the guesses of one visitor do not keep out another
+	assert.Nil(t, unlock("203.0.113.2", "open-sesame"))
+}
+
+func TestRemoveLinkPassword(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	link := createLink(t, token, model.CreateLinkRequest{
+		Title:    "Internal Docs",
+		ShortUrl: "docs",
+		LongUrl:  "https://example.com/internal/docs",
+		IsActive: true,
+		Password: "open-sesame",
+	})
+
+	isActive := true
+	password := ""
+	bodyJson, err := json.Marshal(model.UpdateLinkRequest{
+		Title:    link.Title,
+		ShortUrl: link.ShortUrl,
+		LongUrl:  link.LongUrl,
+		IsActive: &isActive,
+		Password: &password,
+	})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/links/"+link.ID, strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = app.Test(httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+}
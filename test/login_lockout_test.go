@@ -0,0 +1,117 @@
+package test
+
+import (
+	"devshort-backend/internal/model"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loginStatus(t *testing.T, userID string, password string) *http.Response {
+	return postJson(t, "/api/users/_login", model.LoginUserRequest{ID: userID, Password: password})
+}
+
+// failLogins fails total logins of userID, waiting out the backoff in between
+func failLogins(t *testing.T, userID string, total int) {
+	for i := 0; i < total; i++ {
+		clock.Advance(10 * time.Minute)
+		assert.Equal(t, http.StatusUnauthorized, loginStatus(t, userID, "wrong-password").StatusCode)
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "lock", "rahasia123", "Lock", "lock@example.com")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, loginStatus(t, "lock", "wrong-password").StatusCode)
+	}
+
+	// even the right password has to wait
+	response := loginStatus(t, "lock", "rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "1", response.Header.Get("Retry-After"))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, http.StatusUnauthorized, loginStatus(t, "lock", "wrong-password").StatusCode)
+
+	// every failure doubles the wait
+	response = loginStatus(t, "lock", "rahasia123")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "2", response.Header.Get("Retry-After"))
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, http.StatusOK, loginStatus(t, "lock", "rahasia123").StatusCode)
+
+	// a successful login starts over
+	assert.Equal(t, http.StatusUnauthorized, loginStatus(t, "lock", "wrong-password").StatusCode)
+	assert.Equal(t, http.StatusOK, loginStatus(t, "lock", "rahasia123").StatusCode)
+}
+
+func TestLoginBackoffPerIp(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "lock", "rahasia123", "Lock", "lock@example.com")
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, http.StatusUnauthorized, loginStatus(t, "unknown"+strconv.Itoa(i), "wrong-password").StatusCode)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, loginStatus(t, "lock", "rahasia123").StatusCode)
+}
+
+func TestLoginLockout(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "lock", "rahasia123", "Lock", "lock@example.com")
+	failLogins(t, "lock", 10)
+
+	user := GetFirstUser(t)
+	assert.NotNil(t, user.LockedUntil)
+
+	clock.Advance(10 * time.Minute)
+	response := loginStatus(t, "lock", "rahasia123")
+	assert.Equal(t, http.StatusLocked, response.StatusCode)
+	assert.Equal(t, "300", response.Header.Get("Retry-After"))
+	assert.Equal(t, "account_locked", readWebResponse[any](t, response).Code)
+
+	// without the password a locked account looks like a wrong password
+	clock.Advance(time.Minute)
+	response = loginStatus(t, "lock", "wrong-password")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "invalid_credentials", readWebResponse[any](t, response).Code)
+
+	// the lock runs out by itself
+	clock.Advance(5 * time.Minute)
+	assert.Equal(t, http.StatusOK, loginStatus(t, "lock", "rahasia123").StatusCode)
+	assert.Nil(t, GetFirstUser(t).LockedUntil)
+}
+
+func TestUnlockAccount(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "lock", "rahasia123", "Lock", "lock@example.com")
+	failLogins(t, "lock", 10)
+
+	clock.Advance(10 * time.Minute)
+	assert.Equal(t, http.StatusLocked, loginStatus(t, "lock", "rahasia123").StatusCode)
+
+	token := lastMailToken(t, "lock@example.com")
+	response := postJson(t, "/api/users/_unlock", model.UnlockAccountRequest{Token: token})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, http.StatusOK, loginStatus(t, "lock", "rahasia123").StatusCode)
+
+	response = postJson(t, "/api/users/_unlock", model.UnlockAccountRequest{Token: token})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestLockoutUnverifiedEmailGetsNoUnlockLink(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "lock", "rahasia123", "Lock", "lock-unverified@example.com")
+	before := len(readMails(t, "lock-unverified@example.com"))
+
+	failLogins(t, "lock", 10)
+
+	assert.NotNil(t, GetFirstUser(t).LockedUntil)
+	assert.Equal(t, before, len(readMails(t, "lock-unverified@example.com")))
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	code, err := usecase.GenerateTotpCode(secret, at)
+	assert.Nil(t, err)
+	return code
+}
+
+// enableTotp turns two factor login on for the user behind token and returns
+// the secret and recovery codes. The code of the current step is spent.
+func enableTotp(t *testing.T, token string) (string, []string) {
+	response, enrollment := authorizedJson[model.TotpEnrollmentResponse](t, http.MethodPost, "/api/users/_current/mfa/totp", token, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, enrollment.Data.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.Data.Uri, "otpauth://totp/"))
+
+	code := totpCode(t, enrollment.Data.Secret, time.Now())
+	response, confirmed := authorizedJson[model.RecoveryCodesResponse](t, http.MethodPost, "/api/users/_current/mfa/totp/_confirm", token,
+		model.ConfirmTotpRequest{Code: code})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	return enrollment.Data.Secret, confirmed.Data.RecoveryCodes
+}
+
+func loginMfa(t *testing.T, mfaToken string, code string) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	response := postJson(t, "/api/users/_login/_mfa", model.LoginMfaRequest{MfaToken: mfaToken, Code: code})
+	return response, readWebResponse[model.UserResponse](t, response)
+}
+
+func TestEnableTotp(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+	_, recoveryCodes := enableTotp(t, token)
+	assert.Len(t, recoveryCodes, 10)
+
+	response, current := authorizedJson[model.UserResponse](t, http.MethodGet, "/api/users/_current", token, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, current.Data.MfaEnabled)
+
+	response, _ = authorizedJson[model.TotpEnrollmentResponse](t, http.MethodPost, "/api/users/_current/mfa/totp", token, nil)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func TestConfirmTotpWrongCode(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+
+	response, _ := authorizedJson[model.TotpEnrollmentResponse](t, http.MethodPost, "/api/users/_current/mfa/totp", token, nil)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, _ = authorizedJson[model.RecoveryCodesResponse](t, http.MethodPost, "/api/users/_current/mfa/totp/_confirm", token,
+		model.ConfirmTotpRequest{Code: "000000"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// login stays single factor until the secret is confirmed
+	assert.NotEmpty(t, login(t, "mfa", "rahasia123").Token)
+}
+
+func TestLoginWithTotp(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+	secret, _ := enableTotp(t, token)
+
+	challenge := login(t, "mfa", "rahasia123")
+	assert.True(t, challenge.MfaRequired)
+	assert.NotEmpty(t, challenge.MfaToken)
+	assert.Empty(t, challenge.Token)
+	assert.Empty(t, challenge.RefreshToken)
+
+	// codes up to the one spent on the confirmation can not be replayed
+	response, _ := loginMfa(t, challenge.MfaToken, totpCode(t, secret, time.Now().Add(-30*time.Second)))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	challenge = login(t, "mfa", "rahasia123")
+	response, responseBody := loginMfa(t, challenge.MfaToken, totpCode(t, secret, time.Now().Add(30*time.Second)))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.Token)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodGet, "/api/users/_current", responseBody.Data.Token))
+}
+
+func TestLoginMfaTokenIsSpentOnFailure(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+	secret, _ := enableTotp(t, token)
+
+	challenge := login(t, "mfa", "rahasia123")
+	response, _ := loginMfa(t, challenge.MfaToken, "000000")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	response, _ = loginMfa(t, challenge.MfaToken, totpCode(t, secret, time.Now().Add(30*time.Second)))
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestLoginMfaFailuresCountTowardLockout(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+	enableTotp(t, token)
+
+	// the right password alone does not forget the wrong codes
+	for i := 0; i < 10; i++ {
+		clock.Advance(10 * time.Minute)
+		challenge := login(t, "mfa", "rahasia123")
+		response, responseBody := loginMfa(t, challenge.MfaToken, "000000")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, "invalid_mfa_code", responseBody.Code)
+	}
+
+	clock.Advance(10 * time.Minute)
+	response := loginStatus(t, "mfa", "rahasia123")
+	assert.Equal(t, http.StatusLocked, response.StatusCode)
+	assert.Equal(t, "account_locked", readWebResponse[any](t, response).Code)
+}
+
+func TestLoginWithRecoveryCode(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+	_, recoveryCodes := enableTotp(t, token)
+
+	challenge := login(t, "mfa", "rahasia123")
+	response, responseBody := loginMfa(t, challenge.MfaToken, strings.ToUpper(recoveryCodes[0]))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.Token)
+
+	challenge = login(t, "mfa", "rahasia123")
+	response, _ = loginMfa(t, challenge.MfaToken, recoveryCodes[0])
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestDisableTotp(t *testing.T) {
+	token := createUserAndGetToken(t, "mfa", "rahasia123", "Mfa")
+	_, recoveryCodes := enableTotp(t, token)
+
+	response, _ := authorizedJson[bool](t, http.MethodDelete, "/api/users/_current/mfa/totp", token, model.DisableTotpRequest{Code: "000000"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, _ = authorizedJson[bool](t, http.MethodDelete, "/api/users/_current/mfa/totp", token, model.DisableTotpRequest{Code: recoveryCodes[1]})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	assert.NotEmpty(t, login(t, "mfa", "rahasia123").Token)
+}
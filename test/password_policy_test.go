@@ -0,0 +1,78 @@
+package test
+
+import (
+	"devshort-backend/internal/model"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func violationCodes(t *testing.T, details []model.FieldError) []string {
+	codes := make([]string, len(details))
+	for i, detail := range details {
+		assert.Equal(t, "password", detail.Field)
+		codes[i] = detail.Code
+	}
+	return codes
+}
+
+func TestRegisterPasswordTooShort(t *testing.T) {
+	ClearAll()
+
+	response, responseBody := register(t, model.RegisterUserRequest{ID: "zhaka", Password: "x", Name: "Zhaka Hidayat"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "invalid_password", responseBody.Code)
+	assert.Equal(t, []string{"password_too_short"}, violationCodes(t, responseBody.Details))
+	assert.NotEmpty(t, responseBody.Details[0].Message)
+}
+
+func TestRegisterPasswordContainsUserInfo(t *testing.T) {
+	ClearAll()
+
+	response, responseBody := register(t, model.RegisterUserRequest{ID: "zhaka", Password: "my-ZHAKA-secret", Name: "Zhaka Hidayat"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, []string{"password_contains_user_info"}, violationCodes(t, responseBody.Details))
+
+	response, responseBody = register(t, model.RegisterUserRequest{ID: "zhaka", Password: "hidayat-2026", Name: "Zhaka Hidayat"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, []string{"password_contains_user_info"}, violationCodes(t, responseBody.Details))
+}
+
+func TestRegisterBreachedPassword(t *testing.T) {
+	ClearAll()
+
+	for _, password := range []string{"password123", "12345678", "qwertyuiop"} {
+		response, responseBody := register(t, model.RegisterUserRequest{ID: "zhaka", Password: password, Name: "Zhaka Hidayat"})
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, []string{"password_breached"}, violationCodes(t, responseBody.Details))
+	}
+
+	response, _ := register(t, model.RegisterUserRequest{ID: "zhaka", Password: "correct horse battery", Name: "Zhaka Hidayat"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestUpdatePasswordPolicy(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	response, responseBody := authorizedJson[model.UserResponse](t, http.MethodPatch, "/api/users/_current", token,
+		model.UpdateUserRequest{Password: "short"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, "invalid_password", responseBody.Code)
+	assert.Equal(t, []string{"password_too_short"}, violationCodes(t, responseBody.Details))
+
+	login(t, "zhaka", "rahasia123")
+}
+
+func TestPasswordResetPolicy(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "reset", "rahasia123", "Reset", "reset@example.com")
+	token := requestResetToken(t, "reset@example.com")
+
+	response := postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "letmein123"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// the token survives a rejected password
+	response = postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "baru12345"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"devshort-backend/internal/entity"
+	"devshort-backend/internal/model"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func registerWithEmail(t *testing.T, userID, password, name, email string) {
+	response, _ := register(t, model.RegisterUserRequest{ID: userID, Password: password, Name: name, Email: email})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+// registerVerified registers a user and verifies their email
+func registerVerified(t *testing.T, userID, password, name, email string) {
+	registerWithEmail(t, userID, password, name, email)
+	response, _ := verifyEmail(t, lastMailToken(t, email))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+// readMails returns the mails sent to recipient, oldest first
+func readMails(t *testing.T, recipient string) []string {
+	files, err := filepath.Glob(filepath.Join(mailDir, "*-"+recipient+".eml"))
+	assert.Nil(t, err)
+	sort.Strings(files)
+
+	mails := make([]string, len(files))
+	for i, file := range files {
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		mails[i] = string(content)
+	}
+	return mails
+}
+
+// lastMailToken returns the token linked in the latest mail to recipient
+func lastMailToken(t *testing.T, recipient string) string {
+	mails := readMails(t, recipient)
+	if !assert.NotEmpty(t, mails) {
+		return ""
+	}
+	match := mailTokenPattern.FindStringSubmatch(mails[len(mails)-1])
+	if !assert.NotNil(t, match) {
+		return ""
+	}
+	return match[1]
+}
+
+func requestResetToken(t *testing.T, email string) string {
+	before := len(readMails(t, email))
+
+	response := postJson(t, "/api/users/_password_reset", model.RequestPasswordResetRequest{Email: email})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, before+1, len(readMails(t, email)))
+	return lastMailToken(t, email)
+}
+
+func TestPasswordReset(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "reset", "rahasia123", "Reset", "reset@example.com")
+	session := login(t, "reset", "rahasia123")
+
+	token := requestResetToken(t, "reset@example.com")
+
+	response := postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "baru12345"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// the old password and every session are gone
+	response = postJson(t, "/api/users/_login", model.LoginUserRequest{ID: "reset", Password: "rahasia123"})
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, authorizedStatus(t, http.MethodGet, "/api/users/_current", session.Token))
+	refreshResponse, _ := refresh(t, session.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, refreshResponse.StatusCode)
+
+	login(t, "reset", "baru12345")
+}
+
+func TestPasswordResetDeletesApiKeys(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "reset", "rahasia123", "Reset", "reset@example.com")
+	session := login(t, "reset", "rahasia123")
+	_, created := createApiKey(t, session.Token, model.CreateApiKeyRequest{
+		Name:   "CI pipeline",
+		Scopes: []string{model.ScopeLinksRead},
+	})
+	assert.Equal(t, http.StatusOK, apiKeyStatus(t, http.MethodGet, "/api/links", created.Data.Key))
+
+	token := requestResetToken(t, "reset@example.com")
+	response := postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "baru12345"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// a key made with the old password stops working with it
+	assert.Equal(t, http.StatusUnauthorized, apiKeyStatus(t, http.MethodGet, "/api/links", created.Data.Key))
+
+	var count int64
+	assert.Nil(t, db.Model(new(entity.ApiKey)).Where("user_id = ?", "reset").Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestPasswordResetTokenSingleUse(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "reset", "rahasia123", "Reset", "reset@example.com")
+	token := requestResetToken(t, "reset@example.com")
+
+	response := postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "baru12345"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "lagi12345"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestPasswordResetNewRequestReplacesToken(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "reset", "rahasia123", "Reset", "reset@example.com")
+	first := requestResetToken(t, "reset@example.com")
+	second := requestResetToken(t, "reset@example.com")
+
+	response := postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: first, Password: "baru12345"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response = postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: second, Password: "baru12345"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestPasswordResetExpiredToken(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "reset", "rahasia123", "Reset", "reset@example.com")
+	token := requestResetToken(t, "reset@example.com")
+
+	err := db.Exec("update user_tokens set expires_at = 0").Error
+	assert.Nil(t, err)
+
+	response := postJson(t, "/api/users/_password_reset/_confirm", model.ConfirmPasswordResetRequest{Token: token, Password: "baru12345"})
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestPasswordResetUnknownEmail(t *testing.T) {
+	ClearAll()
+
+	response := postJson(t, "/api/users/_password_reset", model.RequestPasswordResetRequest{Email: "nobody@example.com"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, readMails(t, "nobody@example.com"))
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	ClearAll()
+	registerVerified(t, "reset", "rahasia123", "Reset", "reset@example.com")
+
+	response := postJson(t, "/api/users", model.RegisterUserRequest{ID: "other", Password: "rahasia123", Name: "Other", Email: "RESET@example.com"})
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+}
+
+func TestPasswordResetUnverifiedEmail(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "reset", "rahasia123", "Reset", "unverified@example.com")
+	before := len(readMails(t, "unverified@example.com"))
+
+	response := postJson(t, "/api/users/_password_reset", model.RequestPasswordResetRequest{Email: "unverified@example.com"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, before, len(readMails(t, "unverified@example.com")))
+}
+
+func TestRegisterEmailHeldUnverified(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "squatter", "rahasia123", "Squatter", "owner@example.com")
+	squatterToken := lastMailToken(t, "owner@example.com")
+
+	// the real owner gets the address, the squatter loses it with its pending link
+	response := postJson(t, "/api/users", model.RegisterUserRequest{ID: "owner", Password: "rahasia123", Name: "Owner", Email: "owner@example.com"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, _ = verifyEmail(t, squatterToken)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, body := verifyEmail(t, lastMailToken(t, "owner@example.com"))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "owner", body.Data.ID)
+
+	squatter := new(entity.User)
+	assert.Nil(t, db.Where("id = ?", "squatter").Take(squatter).Error)
+	assert.Nil(t, squatter.Email)
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"context"
+	"devshort-backend/internal/config"
+	"devshort-backend/internal/delivery/http/middleware"
+	"devshort-backend/internal/model"
+	"devshort-backend/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRateLimitedApp serves GET / behind a limit of 3 requests per minute. The
+// X-User header stands in for the auth middleware.
+func newRateLimitedApp() *fiber.App {
+	limiter := usecase.NewRateLimiter(log, usecase.NewMemoryRateLimitStore(), clock)
+	limit := &usecase.RateLimit{Name: "test", Limit: 3, Window: time.Minute}
+
+	limited := config.NewFiber(viperConfig)
+	limited.Use(func(ctx *fiber.Ctx) error {
+		if user := ctx.Get("X-User"); user != "" {
+			ctx.Locals("auth", &model.Auth{ID: user})
+		}
+		return ctx.Next()
+	})
+	limited.Get("/", middleware.NewRateLimit(limiter, limit), func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+	return limited
+}
+
+func limitedGet(t *testing.T, limited *fiber.App, user string) *http.Response {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if user != "" {
+		request.Header.Set("X-User", user)
+	}
+	response, err := limited.Test(request)
+	require.Nil(t, err)
+	return response
+}
+
+func TestRateLimit(t *testing.T) {
+	limited := newRateLimitedApp()
+
+	for _, remaining := range []string{"2", "1", "0"} {
+		response := limitedGet(t, limited, "")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "3;w=60", response.Header.Get("RateLimit-Policy"))
+		assert.Equal(t, "3", response.Header.Get("RateLimit-Limit"))
+		assert.Equal(t, remaining, response.Header.Get("RateLimit-Remaining"))
+	}
+
+	response := limitedGet(t, limited, "")
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal(t, "20", response.Header.Get("Retry-After"))
+	assert.Equal(t, "0", response.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", response.Header.Get("RateLimit-Reset"))
+
+	// one request comes back every 20 seconds
+	clock.Advance(20 * time.Second)
+	assert.Equal(t, http.StatusOK, limitedGet(t, limited, "").StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, limitedGet(t, limited, "").StatusCode)
+
+	clock.Advance(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, limitedGet(t, limited, "").StatusCode)
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	limited := newRateLimitedApp()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, limitedGet(t, limited, "budi").StatusCode)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, limitedGet(t, limited, "budi").StatusCode)
+
+	// other users and anonymous clients have their own buckets
+	assert.Equal(t, http.StatusOK, limitedGet(t, limited, "joko").StatusCode)
+	assert.Equal(t, http.StatusOK, limitedGet(t, limited, "").StatusCode)
+}
+
+func TestRateLimitPerClientBehindProxy(t *testing.T) {
+	proxiedGet := func(limited *fiber.App, ip string) int {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("X-Real-IP", ip)
+		response, err := limited.Test(request)
+		require.Nil(t, err)
+		return response.StatusCode
+	}
+
+	// requests of the test client come from 0.0.0.0
+	viperConfig.Set("web.proxy_header", "X-Real-IP")
+	viperConfig.Set("web.trusted_proxies", []string{"0.0.0.0"})
+	limited := newRateLimitedApp()
+	viperConfig.Set("web.trusted_proxies", []string{"10.0.0.1"})
+	untrusted := newRateLimitedApp()
+	viperConfig.Set("web.proxy_header", "")
+	viperConfig.Set("web.trusted_proxies", []string{})
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, proxiedGet(limited, "203.0.113.1"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, proxiedGet(limited, "203.0.113.1"))
+	assert.Equal(t, http.StatusOK, proxiedGet(limited, "203.0.113.2"))
+
+	// the header of a client that is not a trusted proxy is ignored
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, proxiedGet(untrusted, "203.0.113.1"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, proxiedGet(untrusted, "203.0.113.2"))
+}
+
+func TestRateLimitHeadersOnRoutes(t *testing.T) {
+	ClearAll()
+	registerWithEmail(t, "limit", "rahasia123", "Limit", "limit@example.com")
+
+	response := postJson(t, "/api/users/_login", model.LoginUserRequest{ID: "limit", Password: "rahasia123"})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "100000;w=60", response.Header.Get("RateLimit-Policy"))
+	assert.NotEmpty(t, response.Header.Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitBeforeAuthentication(t *testing.T) {
+	// a second app with a small auth_ip limit, config is only read at bootstrap
+	viperConfig.Set("rate_limit.auth_ip.limit", 3)
+	limited := config.NewFiber(viperConfig)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config.Bootstrap(&config.BootstrapConfig{
+		DB:       db,
+		App:      limited,
+		Log:      log,
+		Validate: validate,
+		Config:   viperConfig,
+		Clock:    clock,
+		Context:  ctx,
+	})
+	viperConfig.Set("rate_limit.auth_ip.limit", 100000)
+
+	bad := func(header string, value string) int {
+		request := httptest.NewRequest(http.MethodGet, "/api/links", nil)
+		request.Header.Set(header, value)
+		response, err := limited.Test(request)
+		require.Nil(t, err)
+		return response.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, bad("Authorization", "Bearer forged"))
+	assert.Equal(t, http.StatusUnauthorized, bad("X-API-Key", usecase.ApiKeyPrefix+"forged"))
+	assert.Equal(t, http.StatusUnauthorized, bad("Authorization", "Bearer forged"))
+	assert.Equal(t, http.StatusTooManyRequests, bad("Authorization", "Bearer forged"))
+	assert.Equal(t, http.StatusTooManyRequests, bad("X-API-Key", usecase.ApiKeyPrefix+"forged"))
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"devshort-backend/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listSessions(t *testing.T, token string) (*http.Response, *model.WebResponse[[]model.SessionResponse]) {
+	return authorizedJson[[]model.SessionResponse](t, http.MethodGet, "/api/users/_current/sessions", token, nil)
+}
+
+func TestListSessions(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	bodyJson, err := json.Marshal(model.LoginUserRequest{ID: "zhaka", Password: "rahasia123"})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_login", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	tokens := new(model.WebResponse[model.UserResponse])
+	err = json.Unmarshal(bytes, tokens)
+	assert.Nil(t, err)
+
+	response, responseBody := listSessions(t, tokens.Data.Token)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// one session from the helper, one from the login above
+	assert.Len(t, responseBody.Data, 2)
+	current := 0
+	for _, session := range responseBody.Data {
+		assert.NotEmpty(t, session.ID)
+		assert.NotZero(t, session.LastSeenAt)
+		if session.Current {
+			current++
+			assert.Equal(t, "Safari", session.Browser)
+			assert.Equal(t, "macOS", session.OS)
+		}
+	}
+	assert.Equal(t, 1, current)
+}
+
+func TestDeleteSession(t *testing.T) {
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	other := login(t, "zhaka", "rahasia123")
+
+	_, sessions := listSessions(t, token)
+	var otherSessionId string
+	for _, session := range sessions.Data {
+		if !session.Current {
+			otherSessionId = session.ID
+		}
+	}
+	assert.NotEmpty(t, otherSessionId)
+
+	status := authorizedStatus(t, http.MethodDelete, "/api/users/_current/sessions/"+otherSessionId, token)
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.Equal(t, http.StatusUnauthorized, authorizedStatus(t, http.MethodGet, "/api/users/_current", other.Token))
+	response, _ := refresh(t, other.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	_, sessions = listSessions(t, token)
+	assert.Len(t, sessions.Data, 1)
+	assert.True(t, sessions.Data[0].Current)
+}
+
+func TestDeleteSessionOtherUser(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	victim := login(t, "zhaka", "rahasia123")
+	token := registerAndLogin(t, "budi", "rahasia123", "Budi Santoso")
+
+	_, sessions := listSessions(t, victim.Token)
+	status := authorizedStatus(t, http.MethodDelete, "/api/users/_current/sessions/"+sessions.Data[0].ID, token)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodGet, "/api/users/_current", victim.Token))
+}
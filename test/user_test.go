@@ -17,57 +17,41 @@ import (
 // Helper function to create a user and get JWT token
 func createUserAndGetToken(t *testing.T, userID, password, name string) string {
 	ClearAll()
-	
-	// Register user
-	requestBody := model.RegisterUserRequest{
-		ID:       userID,
-		Password: password,
-		Name:     name,
-	}
-
-	bodyJson, err := json.Marshal(requestBody)
-	assert.Nil(t, err)
+	return registerAndLogin(t, userID, password, name)
+}
 
-	request := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(string(bodyJson)))
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Accept", "application/json")
+// Helper function to register a user
+func register(t *testing.T, requestBody model.RegisterUserRequest) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	response := postJson(t, "/api/users", requestBody)
+	return response, readWebResponse[model.UserResponse](t, response)
+}
 
-	response, err := app.Test(request)
-	assert.Nil(t, err)
+// Helper function to add another user next to existing data and get JWT token
+func registerAndLogin(t *testing.T, userID, password, name string) string {
+	response, _ := register(t, model.RegisterUserRequest{ID: userID, Password: password, Name: name})
 	assert.Equal(t, http.StatusOK, response.StatusCode)
 
-	// Login to get token
-	loginBody := model.LoginUserRequest{
-		ID:       userID,
-		Password: password,
-	}
-
-	loginJson, err := json.Marshal(loginBody)
-	assert.Nil(t, err)
-
-	loginReq := httptest.NewRequest(http.MethodPost, "/api/users/_login", strings.NewReader(string(loginJson)))
-	loginReq.Header.Set("Content-Type", "application/json")
-	loginReq.Header.Set("Accept", "application/json")
-
-	loginRes, err := app.Test(loginReq)
-	assert.Nil(t, err)
-	assert.Equal(t, http.StatusOK, loginRes.StatusCode)
-
-	loginBytes, err := io.ReadAll(loginRes.Body)
-	assert.Nil(t, err)
+	return login(t, userID, password).Token
+}
 
-	loginResponse := new(model.WebResponse[model.UserResponse])
-	err = json.Unmarshal(loginBytes, loginResponse)
-	assert.Nil(t, err)
+// Helper function to login an existing user
+func login(t *testing.T, userID, password string) model.UserResponse {
+	response := postJson(t, "/api/users/_login", model.LoginUserRequest{ID: userID, Password: password})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return readWebResponse[model.UserResponse](t, response).Data
+}
 
-	return loginResponse.Data.Token
+// Helper function to trade a refresh token for a new token pair
+func refresh(t *testing.T, refreshToken string) (*http.Response, *model.WebResponse[model.UserResponse]) {
+	response := postJson(t, "/api/users/_refresh", model.RefreshTokenRequest{RefreshToken: refreshToken})
+	return response, readWebResponse[model.UserResponse](t, response)
 }
 
 func TestRegister(t *testing.T) {
 	ClearAll()
 	requestBody := model.RegisterUserRequest{
 		ID:       "zhaka",
-		Password: "rahasia",
+		Password: "rahasia123",
 		Name:     "Zhaka Hidayat",
 	}
 
@@ -130,7 +114,7 @@ func TestRegisterDuplicate(t *testing.T) {
 	// Register first user
 	firstRequestBody := model.RegisterUserRequest{
 		ID:       "zhaka",
-		Password: "rahasia",
+		Password: "rahasia123",
 		Name:     "Zhaka Hidayat",
 	}
 
@@ -148,7 +132,7 @@ func TestRegisterDuplicate(t *testing.T) {
 	// Try to register duplicate user
 	duplicateRequestBody := model.RegisterUserRequest{
 		ID:       "zhaka", // Same ID
-		Password: "rahasia123",
+		Password: "rahasia456",
 		Name:     "Another Name",
 	}
 
@@ -179,7 +163,7 @@ func TestLogin(t *testing.T) {
 	// Register user first
 	registerBody := model.RegisterUserRequest{
 		ID:       "zhaka",
-		Password: "rahasia",
+		Password: "rahasia123",
 		Name:     "Zhaka Hidayat",
 	}
 
@@ -197,7 +181,7 @@ func TestLogin(t *testing.T) {
 	// Login
 	requestBody := model.LoginUserRequest{
 		ID:       "zhaka",
-		Password: "rahasia",
+		Password: "rahasia123",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -229,7 +213,7 @@ func TestLoginWrongUsername(t *testing.T) {
 	// Register user first
 	registerBody := model.RegisterUserRequest{
 		ID:       "zhaka",
-		Password: "rahasia",
+		Password: "rahasia123",
 		Name:     "Zhaka Hidayat",
 	}
 
@@ -247,7 +231,7 @@ func TestLoginWrongUsername(t *testing.T) {
 	// Login with wrong username
 	requestBody := model.LoginUserRequest{
 		ID:       "wrong",
-		Password: "rahasia",
+		Password: "rahasia123",
 	}
 
 	bodyJson, err := json.Marshal(requestBody)
@@ -277,7 +261,7 @@ func TestLoginWrongPassword(t *testing.T) {
 	// Register user first
 	registerBody := model.RegisterUserRequest{
 		ID:       "zhaka",
-		Password: "rahasia",
+		Password: "rahasia123",
 		Name:     "Zhaka Hidayat",
 	}
 
@@ -321,7 +305,7 @@ func TestLoginWrongPassword(t *testing.T) {
 
 func TestLogout(t *testing.T) {
 	// Create user and get JWT token
-	token := createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/users", nil)
 	request.Header.Set("Content-Type", "application/json")
@@ -344,7 +328,7 @@ func TestLogout(t *testing.T) {
 
 func TestLogoutWrongAuthorization(t *testing.T) {
 	// Create user and get JWT token (but don't use it)
-	createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/users", nil)
 	request.Header.Set("Content-Type", "application/json")
@@ -367,7 +351,7 @@ func TestLogoutWrongAuthorization(t *testing.T) {
 
 func TestGetCurrentUser(t *testing.T) {
 	// Create user and get JWT token
-	token := createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
 	request.Header.Set("Content-Type", "application/json")
@@ -393,7 +377,7 @@ func TestGetCurrentUser(t *testing.T) {
 
 func TestGetCurrentUserFailed(t *testing.T) {
 	// Create user and get JWT token (but don't use it)
-	createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
 	request.Header.Set("Content-Type", "application/json")
@@ -416,7 +400,7 @@ func TestGetCurrentUserFailed(t *testing.T) {
 
 func TestGetCurrentUserNoAuthorization(t *testing.T) {
 	// Create user and get JWT token (but don't use it)
-	createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
 	request.Header.Set("Content-Type", "application/json")
@@ -439,7 +423,7 @@ func TestGetCurrentUserNoAuthorization(t *testing.T) {
 
 func TestUpdateUserName(t *testing.T) {
 	// Create user and get JWT token
-	token := createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	requestBody := model.UpdateUserRequest{
 		Name: "Zhaka Hidayat Updated",
@@ -472,7 +456,7 @@ func TestUpdateUserName(t *testing.T) {
 
 func TestUpdateUserPassword(t *testing.T) {
 	// Create user and get JWT token
-	token := createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	requestBody := model.UpdateUserRequest{
 		Password: "rahasialagi",
@@ -512,7 +496,7 @@ func TestUpdateUserPassword(t *testing.T) {
 
 func TestUpdateUserNameAndPassword(t *testing.T) {
 	// Create user and get JWT token
-	token := createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	token := createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	requestBody := model.UpdateUserRequest{
 		Name:     "Zhaka Hidayat Updated",
@@ -554,7 +538,7 @@ func TestUpdateUserNameAndPassword(t *testing.T) {
 
 func TestUpdateFailed(t *testing.T) {
 	// Create user and get JWT token (but don't use it)
-	createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	requestBody := model.UpdateUserRequest{
 		Name:     "Should Fail",
@@ -585,7 +569,7 @@ func TestUpdateFailed(t *testing.T) {
 
 func TestUpdateFailedNoAuthorization(t *testing.T) {
 	// Create user and get JWT token (but don't use it)
-	createUserAndGetToken(t, "zhaka", "rahasia", "Zhaka Hidayat")
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
 
 	requestBody := model.UpdateUserRequest{
 		Name:     "Should Fail",
@@ -612,4 +596,103 @@ func TestUpdateFailedNoAuthorization(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
 	assert.NotNil(t, responseBody.Errors)
-}
\ No newline at end of file
+}
+
+func TestLoginReturnsRefreshToken(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+
+	tokens := login(t, "zhaka", "rahasia123")
+	assert.NotEmpty(t, tokens.Token)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	stored := new(entity.RefreshToken)
+	err := db.Where("user_id = ?", "zhaka").Order("created_at desc").First(stored).Error
+	assert.Nil(t, err)
+	assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash)
+}
+
+func TestRefreshToken(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	tokens := login(t, "zhaka", "rahasia123")
+
+	response, responseBody := refresh(t, tokens.RefreshToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "zhaka", responseBody.Data.ID)
+	assert.NotEmpty(t, responseBody.Data.Token)
+	assert.NotEmpty(t, responseBody.Data.RefreshToken)
+	assert.NotEqual(t, tokens.RefreshToken, responseBody.Data.RefreshToken)
+
+	// the rotated token keeps working
+	response, _ = refresh(t, responseBody.Data.RefreshToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestRefreshTokenInvalid(t *testing.T) {
+	ClearAll()
+
+	response, responseBody := refresh(t, "not-a-refresh-token")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Errors)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	tokens := login(t, "zhaka", "rahasia123")
+
+	_, rotated := refresh(t, tokens.RefreshToken)
+	var sessions, remaining int64
+	assert.Nil(t, db.Model(new(entity.Session)).Where("user_id = ?", "zhaka").Count(&sessions).Error)
+
+	// replaying the first token revokes every token of the login
+	response, _ := refresh(t, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	response, _ = refresh(t, rotated.Data.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// the session ends and the access token of the last rotation stops working
+	assert.Nil(t, db.Model(new(entity.Session)).Where("user_id = ?", "zhaka").Count(&remaining).Error)
+	assert.Equal(t, sessions-1, remaining)
+	response, body := authorizedJson[any](t, http.MethodGet, "/api/users/_current", rotated.Data.Token, nil)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "invalid_token", body.Code)
+
+	// other logins are not affected
+	other := login(t, "zhaka", "rahasia123")
+	response, _ = refresh(t, other.RefreshToken)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodGet, "/api/users/_current", other.Token))
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	tokens := login(t, "zhaka", "rahasia123")
+	other := login(t, "zhaka", "rahasia123")
+
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodDelete, "/api/users", tokens.Token))
+
+	assert.Equal(t, http.StatusUnauthorized, authorizedStatus(t, http.MethodGet, "/api/users/_current", tokens.Token))
+	response, _ := refresh(t, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// other sessions stay logged in
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodGet, "/api/users/_current", other.Token))
+}
+
+func TestLogoutEverywhere(t *testing.T) {
+	createUserAndGetToken(t, "zhaka", "rahasia123", "Zhaka Hidayat")
+	first := login(t, "zhaka", "rahasia123")
+	second := login(t, "zhaka", "rahasia123")
+
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodDelete, "/api/users/_current/sessions", first.Token))
+
+	assert.Equal(t, http.StatusUnauthorized, authorizedStatus(t, http.MethodGet, "/api/users/_current", first.Token))
+	assert.Equal(t, http.StatusUnauthorized, authorizedStatus(t, http.MethodGet, "/api/users/_current", second.Token))
+	response, _ := refresh(t, second.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// logging in again works right away
+	third := login(t, "zhaka", "rahasia123")
+	assert.Equal(t, http.StatusOK, authorizedStatus(t, http.MethodGet, "/api/users/_current", third.Token))
+}
+